@@ -0,0 +1,66 @@
+package gopartial
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	type scalar int
+
+	t.Run("Defaults to the json tag, SkipConditions and Updaters", func(t *testing.T) {
+		dest := destination{Field0: "bar"}
+		result, err := Apply(&dest, map[string]interface{}{"field0": "foo", "field1": "foo", "field5": 1.0})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Field1", "Field5"}, result.Updated)
+		require.Equal(t, destination{Field0: "bar", Field1: "foo", Field5: 1}, dest)
+	})
+
+	t.Run("Field names without tag name", func(t *testing.T) {
+		dest := destination{}
+		result, err := Apply(&dest, map[string]interface{}{"Field1": "foo"}, WithTagName(""))
+		require.NoError(t, err)
+		require.Equal(t, []string{"Field1"}, result.Updated)
+		require.Equal(t, "foo", dest.Field1)
+	})
+
+	t.Run("Skip conditions and updaters", func(t *testing.T) {
+		dest := destination{}
+		_, err := Apply(&dest, map[string]interface{}{"field0": "foo", "field9": "2017-11-22T20:30:26.716Z"},
+			WithSkipConditions(), WithUpdaters(TimeUpdater))
+		require.NoError(t, err)
+		require.Equal(t, "foo", dest.Field0)
+		require.Equal(t, 2017, dest.Field9.Year())
+	})
+
+	t.Run("Atomic", func(t *testing.T) {
+		dest := destination{Field1: "bar"}
+		_, err := Apply(&dest, map[string]interface{}{"field1": "foo", "field13": 1000}, WithAtomic())
+		require.Error(t, err)
+		require.Equal(t, destination{Field1: "bar"}, dest)
+	})
+
+	t.Run("Collect errors", func(t *testing.T) {
+		dest := destination{}
+		result, err := Apply(&dest, map[string]interface{}{"field1": "foo", "field5": "x", "field13": 1000}, WithCollectErrors())
+		var fieldErrors FieldErrors
+		require.True(t, errors.As(err, &fieldErrors))
+		require.Len(t, fieldErrors, 2)
+		require.Equal(t, []string{"Field1"}, result.Updated)
+	})
+
+	t.Run("Merge patch", func(t *testing.T) {
+		dest := destination{Field5: 1}
+		_, err := Apply(&dest, map[string]interface{}{"field5": nil}, WithMergePatch())
+		require.NoError(t, err)
+		require.Equal(t, 0, dest.Field5)
+	})
+
+	t.Run("Destination must point to a struct", func(t *testing.T) {
+		var dest scalar
+		_, err := Apply(&dest, map[string]interface{}{})
+		require.Equal(t, ErrDestinationMustBeStructType, err)
+	})
+}
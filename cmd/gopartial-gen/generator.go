@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	libraryPath = "github.com/nandaryanizar/gopartial"
+	timePath    = "time"
+	nullPath    = "github.com/guregu/null"
+)
+
+// coercion describes how a scalar field type is converted from a partial value
+type coercion struct {
+	// fn is the Coerce function of the gopartial package
+	fn string
+	// bits is the bit size argument of fn, empty when fn takes none
+	bits string
+	// result is the type returned by fn
+	result string
+}
+
+// basicCoercions are the coercions of the predeclared types
+var basicCoercions = map[string]coercion{
+	"int":     {"CoerceInt", "0", "int64"},
+	"int8":    {"CoerceInt", "8", "int64"},
+	"int16":   {"CoerceInt", "16", "int64"},
+	"int32":   {"CoerceInt", "32", "int64"},
+	"rune":    {"CoerceInt", "32", "int64"},
+	"int64":   {"CoerceInt", "64", "int64"},
+	"uint":    {"CoerceUint", "0", "uint64"},
+	"uint8":   {"CoerceUint", "8", "uint64"},
+	"byte":    {"CoerceUint", "8", "uint64"},
+	"uint16":  {"CoerceUint", "16", "uint64"},
+	"uint32":  {"CoerceUint", "32", "uint64"},
+	"uint64":  {"CoerceUint", "64", "uint64"},
+	"float32": {"CoerceFloat", "32", "float64"},
+	"float64": {"CoerceFloat", "64", "float64"},
+	"string":  {"CoerceString", "", "string"},
+	"bool":    {"CoerceBool", "", "bool"},
+}
+
+// timeCoercion is the coercion of time.Time
+var timeCoercion = coercion{"CoerceTime", "", "time.Time"}
+
+// nullCoercions are the coercions of the guregu/null types
+var nullCoercions = map[string]coercion{
+	"String": {"CoerceNullString", "", "null.String"},
+	"Float":  {"CoerceNullFloat", "", "null.Float"},
+	"Int":    {"CoerceNullInt", "", "null.Int"},
+	"Bool":   {"CoerceNullBool", "", "null.Bool"},
+	"Time":   {"CoerceNullTime", "", "null.Time"},
+}
+
+// call returns the call of the coercion on the partial value v
+func (c coercion) call(v string) string {
+	if c.bits == "" {
+		return fmt.Sprintf("gopartial.%v(%v)", c.fn, v)
+	}
+	return fmt.Sprintf("gopartial.%v(%v, %v)", c.fn, v, c.bits)
+}
+
+// convert returns the conversion of the coerced value x to typeName
+func (c coercion) convert(x string, typeName string) string {
+	if c.result == typeName {
+		return x
+	}
+	return fmt.Sprintf("%v(%v)", typeName, x)
+}
+
+// structType is a struct type to generate along with the imports of its file
+type structType struct {
+	name    string
+	node    *ast.StructType
+	imports map[string]string
+}
+
+// generator writes the ApplyPartial methods of the struct types of a package
+type generator struct {
+	buf     bytes.Buffer
+	tagName string
+	// generated holds the names of the generated types, nested fields of those types
+	// are updated through their generated method
+	generated map[string]bool
+	// fields is the number of generated field updates
+	fields int
+	// imports holds the import paths referred to by the generated code by package name
+	imports map[string]string
+}
+
+// generate returns the formatted source of the ApplyPartial methods of the struct types
+// typeNames declared in the package of dir
+func generate(dir string, typeNames []string, tagName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgName string
+	structs := make([]structType, 0, len(typeNames))
+	g := &generator{tagName: tagName, generated: make(map[string]bool), imports: make(map[string]string)}
+	for _, typeName := range typeNames {
+		for name, pkg := range pkgs {
+			if s, ok := findStruct(pkg, typeName); ok {
+				pkgName = name
+				structs = append(structs, s)
+				g.generated[typeName] = true
+				break
+			}
+		}
+		if !g.generated[typeName] {
+			return nil, fmt.Errorf("struct type %v not found in %v", typeName, dir)
+		}
+	}
+
+	for _, s := range structs {
+		g.genStruct(s)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by gopartial-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %v\n\n", pkgName)
+	// every field update refers to the library
+	if g.fields > 0 {
+		g.imports["gopartial"] = libraryPath
+	}
+	// the standard library comes first, like goimports does
+	var std, others []string
+	for name, importPath := range g.imports {
+		spec := strconv.Quote(importPath)
+		if name != path.Base(importPath) {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			others = append(others, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+	if len(g.imports) > 0 {
+		fmt.Fprintf(&src, "import (\n%v\n\n%v\n)\n", strings.Join(std, "\n"), strings.Join(others, "\n"))
+	}
+	src.Write(g.buf.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// findStruct looks up the struct type typeName in pkg
+func findStruct(pkg *ast.Package, typeName string) (structType, bool) {
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name != typeName {
+					continue
+				}
+				node, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					return structType{}, false
+				}
+				return structType{name: typeName, node: node, imports: fileImports(file)}, true
+			}
+		}
+	}
+	return structType{}, false
+}
+
+// fileImports returns the import paths of a file by package name
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// use records the imports of the packages referred to by a type written to the generated code
+func (g *generator) use(expr ast.Expr, imports map[string]string) {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if pkg, ok := selector.X.(*ast.Ident); ok {
+				g.imports[pkg.Name] = imports[pkg.Name]
+			}
+			return false
+		}
+		return true
+	})
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// genStruct writes the ApplyPartial method of a struct type
+func (g *generator) genStruct(s structType) {
+	g.printf("\n// ApplyPartial updates d from partial like gopartial.PartialUpdate with the %q tag,\n", g.tagName)
+	g.printf("// gopartial.SkipConditions and gopartial.AllUpdaters, without reflection.\n")
+	g.printf("// Returns list of struct field names that was successfully updated.\n")
+	g.printf("func (d *%v) ApplyPartial(partial map[string]interface{}) ([]string, error) {\n", s.name)
+	g.printf("return d.applyPartial(partial, \"\", \"\")\n")
+	g.printf("}\n\n")
+
+	g.printf("// applyPartial implements ApplyPartial, prefixing the updated names and the failed paths\n")
+	g.printf("func (d *%v) applyPartial(partial map[string]interface{}, namePrefix string, pathPrefix string) ([]string, error) {\n", s.name)
+	g.printf("fieldsUpdated := make([]string, 0, len(partial))\n")
+	for _, field := range s.node.Fields.List {
+		names := make([]string, 0, len(field.Names))
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, embeddedName(field.Type))
+		}
+
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+		if isReadOnly(tag) {
+			continue
+		}
+
+		for _, name := range names {
+			// unexported fields cannot be set
+			if !ast.IsExported(name) {
+				continue
+			}
+			key := name
+			if g.tagName != "" {
+				key = tag.Get(g.tagName)
+			}
+			if key == "" {
+				continue
+			}
+			g.genField(s, name, key, nullAsZero(tag), mergeKey(tag), field.Type)
+		}
+	}
+	g.printf("\nreturn fieldsUpdated, nil\n")
+	g.printf("}\n")
+}
+
+// embeddedName returns the field name of an embedded type
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// nullAsZero reports whether the patch tag resets the field to its zero value on null, like gopartial.NullAsZero
+func nullAsZero(tag reflect.StructTag) bool {
+	for _, option := range strings.Split(tag.Get("patch"), ",") {
+		if strings.Replace(option, " ", "", -1) == "null=zero" {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeKey returns the key merging the elements of a slice of structs of the patch tag, e.g. id for mergeKey=id
+func mergeKey(tag reflect.StructTag) string {
+	for _, option := range strings.Split(tag.Get("patch"), ",") {
+		if kv := strings.SplitN(option, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == "mergeKey" {
+			return strings.TrimSpace(kv[1])
+		}
+	}
+	return ""
+}
+
+// isReadOnly reports whether the props tag marks the field as read only, like gopartial.SkipReadOnly
+func isReadOnly(tag reflect.StructTag) bool {
+	for _, prop := range strings.Split(tag.Get("props"), ",") {
+		if prop == "readonly" {
+			return true
+		}
+	}
+	return false
+}
+
+// scalar returns the coercion of a scalar field type
+func scalar(expr ast.Expr, imports map[string]string) (coercion, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		c, ok := basicCoercions[t.Name]
+		return c, ok
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return coercion{}, false
+		}
+		switch imports[pkg.Name] {
+		case timePath:
+			return timeCoercion, t.Sel.Name == "Time"
+		case nullPath:
+			c, ok := nullCoercions[t.Sel.Name]
+			return c, ok
+		}
+	}
+	return coercion{}, false
+}
+
+// genField writes the update of one field from the partial value of its key,
+// null resets the field to its zero value when nullAsZero is true, and arrays are merged
+// into the elements by mergeKey when it is not empty
+func (g *generator) genField(s structType, name string, key string, nullAsZero bool, mergeKey string, expr ast.Expr) {
+	g.fields++
+	typeName := types.ExprString(expr)
+	reject := func(reason string) string {
+		return fmt.Sprintf("gopartial.NewFieldError(%q, %q, pathPrefix+%q, %q, &d.%v, v, %v)", s.name, name, key, key, name, reason)
+	}
+
+	g.printf("\nif v, ok := partial[%q]; ok {\n", key)
+	defer g.printf("}\n")
+
+	if nullAsZero {
+		g.use(expr, s.imports)
+		g.printf("if v == nil {\nvar zero %v\nd.%v = zero\nfieldsUpdated = append(fieldsUpdated, namePrefix+%q)\n", typeName, name, name)
+		g.printf("} else {\n")
+		defer g.printf("}\n")
+	}
+
+	if mergeKey != "" {
+		g.genMerge(name, key, mergeKey, reject)
+		return
+	}
+
+	if c, ok := scalar(expr, s.imports); ok {
+		if c.convert("x", typeName) != "x" {
+			g.use(expr, s.imports)
+		}
+		g.printf("x, reason := %v\n", c.call("v"))
+		g.printf("if reason != \"\" {\nreturn nil, %v\n}\n", reject("reason"))
+		g.printf("d.%v = %v\n", name, c.convert("x", typeName))
+		g.printf("fieldsUpdated = append(fieldsUpdated, namePrefix+%q)\n", name)
+		return
+	}
+
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		elemName := types.ExprString(t.X)
+		// pointers to strings and nullable types are not handled by the updaters
+		if c, ok := scalar(t.X, s.imports); ok && c.fn != "CoerceString" && !strings.HasPrefix(c.fn, "CoerceNull") {
+			g.use(expr, s.imports)
+			g.printf("if p, ok := v.(%v); ok {\nd.%v = p\n", typeName, name)
+			g.printf("} else if v == nil {\nd.%v = nil\n", name)
+			g.printf("} else {\n")
+			g.printf("x, reason := %v\n", c.call("v"))
+			g.printf("if reason != \"\" {\nreturn nil, %v\n}\n", reject("reason"))
+			if y := c.convert("x", elemName); y != "x" {
+				g.printf("y := %v\n", y)
+				g.printf("d.%v = &y\n", name)
+			} else {
+				g.printf("d.%v = &x\n", name)
+			}
+			g.printf("}\n")
+			g.printf("fieldsUpdated = append(fieldsUpdated, namePrefix+%q)\n", name)
+			return
+		}
+		if ident, ok := t.X.(*ast.Ident); ok && g.generated[ident.Name] {
+			g.printf("if m, ok := v.(map[string]interface{}); ok {\n")
+			g.printf("if d.%v == nil {\nd.%v = new(%v)\n}\n", name, name, ident.Name)
+			g.genNested(name, key)
+			g.printf("} else {\n")
+			g.genFallback(name, key, reject)
+			g.printf("}\n")
+			return
+		}
+	case *ast.Ident:
+		if g.generated[t.Name] {
+			g.printf("if m, ok := v.(map[string]interface{}); ok {\n")
+			g.genNested(name, key)
+			g.printf("} else {\n")
+			g.genFallback(name, key, reject)
+			g.printf("}\n")
+			return
+		}
+	case *ast.ArrayType:
+		if c, ok := scalar(t.Elt, s.imports); ok && t.Len == nil {
+			g.use(expr, s.imports)
+			g.printf("if elements, ok := gopartial.Elements(v); ok {\n")
+			g.printf("s := make(%v, len(elements))\n", typeName)
+			g.printf("for i, e := range elements {\n")
+			g.printf("x, reason := %v\n", c.call("e"))
+			g.printf("if reason != \"\" {\nreturn nil, gopartial.NewElementError(namePrefix+%q, pathPrefix+%q, i, &s[i], e, reason)\n}\n", name, key)
+			g.printf("s[i] = %v\n", c.convert("x", types.ExprString(t.Elt)))
+			g.printf("}\n")
+			g.printf("d.%v = s\n", name)
+			g.printf("fieldsUpdated = append(fieldsUpdated, namePrefix+%q)\n", name)
+			g.printf("} else {\n")
+			g.genFallback(name, key, reject)
+			g.printf("}\n")
+			return
+		}
+	case *ast.InterfaceType:
+		if len(t.Methods.List) == 0 {
+			g.printf("d.%v = v\n", name)
+			g.printf("fieldsUpdated = append(fieldsUpdated, namePrefix+%q)\n", name)
+			return
+		}
+	}
+
+	g.genFallback(name, key, reject)
+}
+
+// genNested writes the update of a generated struct field from the nested object m
+func (g *generator) genNested(name string, key string) {
+	g.printf("nested, err := d.%v.applyPartial(m, namePrefix+%q, pathPrefix+%q)\n", name, name+".", key+".")
+	g.printf("if err != nil {\nreturn nil, err\n}\n")
+	g.printf("fieldsUpdated = append(fieldsUpdated, nested...)\n")
+}
+
+// genFallback writes the update of a field through the runtime library
+func (g *generator) genFallback(name string, key string, reject func(reason string) string) {
+	g.printf("updated, updateSuccess, err := gopartial.UpdateField(&d.%v, v, %q, namePrefix+%q, pathPrefix+%q)\n", name, g.tagName, name, key)
+	g.genUpdated(reject)
+}
+
+// genMerge writes the update of a slice of structs field merged by key through the runtime library
+func (g *generator) genMerge(name string, key string, mergeKey string, reject func(reason string) string) {
+	g.printf("updated, updateSuccess, err := gopartial.MergeField(&d.%v, v, %q, %q, namePrefix+%q, pathPrefix+%q)\n", name, g.tagName, mergeKey, name, key)
+	g.genUpdated(reject)
+}
+
+// genUpdated writes the handling of the result of the runtime library
+func (g *generator) genUpdated(reject func(reason string) string) {
+	g.printf("if !updateSuccess {\nerr = %v\n}\n", reject("err"))
+	g.printf("if err != nil {\nreturn nil, err\n}\n")
+	g.printf("fieldsUpdated = append(fieldsUpdated, updated...)\n")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateExample checks that the generated code of the example package is up to date
+func TestGenerateExample(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	want, err := ioutil.ReadFile(filepath.Join(dir, "model_partial.go"))
+	require.NoError(t, err)
+
+	got, err := generate(dir, []string{"Customer", "Address"}, "json")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run go generate in %v", dir)
+}
+
+func writeSource(t *testing.T, src string) string {
+	dir, err := ioutil.TempDir("", "gopartial-gen")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "model.go"), []byte(src), 0644))
+	return dir
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writeSource(t, `package model
+
+import (
+	gotime "time"
+
+	"github.com/guregu/null"
+)
+
+type Status string
+
+type Model struct {
+	Name    string
+	Status  Status
+	Seen    *gotime.Time
+	Scores  []null.Float
+	Parts   []Model `+"`patch:\"null=zero, mergeKey = id\"`"+`
+	private int
+	Skipped int `+"`props:\"readonly,other\"`"+`
+}
+
+type Empty struct {
+	name string
+}
+`)
+
+	tests := []struct {
+		name      string
+		typeNames []string
+		tagName   string
+		contains  []string
+		wantErr   bool
+	}{
+		{
+			name:      "Field names as keys and aliased imports",
+			typeNames: []string{"Model"},
+			tagName:   "",
+			contains: []string{
+				`gotime "time"`,
+				`partial["Name"]`,
+				`gopartial.UpdateField(&d.Status, v, "", namePrefix+"Status", pathPrefix+"Status")`,
+				`v.(*gotime.Time)`,
+				`make([]null.Float, len(elements))`,
+				`gopartial.MergeField(&d.Parts, v, "", "id", namePrefix+"Parts", pathPrefix+"Parts")`,
+			},
+		},
+		{
+			name:      "Fields without the tag are not generated",
+			typeNames: []string{"Model"},
+			tagName:   "json",
+			contains:  []string{`func (d *Model) ApplyPartial(partial map[string]interface{}) ([]string, error)`},
+		},
+		{
+			name:      "Struct without settable fields",
+			typeNames: []string{"Empty"},
+			tagName:   "json",
+			contains:  []string{`func (d *Empty) ApplyPartial`},
+		},
+		{
+			name:      "Unknown type",
+			typeNames: []string{"Unknown"},
+			wantErr:   true,
+		},
+		{
+			name:      "Not a struct type",
+			typeNames: []string{"Status"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generate(dir, tt.typeNames, tt.tagName)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, s := range tt.contains {
+				require.Contains(t, string(got), s)
+			}
+			require.NotContains(t, string(got), "private")
+			require.NotContains(t, string(got), "Skipped")
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Package example holds the types used to check the code generated by gopartial-gen
+// against the runtime library.
+package example
+
+import (
+	"time"
+
+	"github.com/guregu/null"
+)
+
+//go:generate go run ../.. -type=Customer,Address -output=model_partial.go
+
+// Address is a nested generated type
+type Address struct {
+	City    string `json:"city"`
+	Country string `json:"country" props:"readonly"`
+	Zip     *int   `json:"zip"`
+}
+
+// Customer covers every kind of field handled by the generator
+type Customer struct {
+	ID        int64                  `json:"id" props:"readonly"`
+	Name      string                 `json:"name"`
+	Nickname  *string                `json:"nickname"`
+	Score     float64                `json:"score"`
+	Ratio     *float32               `json:"ratio"`
+	Age       int8                   `json:"age"`
+	Rank      int                    `json:"rank" patch:"null=zero"`
+	Visits    *int                   `json:"visits"`
+	Credits   uint16                 `json:"credits"`
+	Active    bool                   `json:"active"`
+	Verified  *bool                  `json:"verified"`
+	Birthday  time.Time              `json:"birthday"`
+	LastSeen  *time.Time             `json:"last_seen"`
+	Email     null.String            `json:"email"`
+	Balance   null.Float             `json:"balance"`
+	Orders    null.Int               `json:"orders"`
+	Premium   null.Bool              `json:"premium"`
+	Joined    null.Time              `json:"joined"`
+	Tags      []string               `json:"tags"`
+	Lucky     []int                  `json:"lucky"`
+	Extra     interface{}            `json:"extra"`
+	Meta      map[string]interface{} `json:"meta"`
+	Address   Address                `json:"address"`
+	Billing   *Address               `json:"billing"`
+	Shipping  []Address              `json:"shipping" patch:"mergeKey=city"`
+	Untagged  string
+	unexposed string
+}
@@ -0,0 +1,365 @@
+// Code generated by gopartial-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"time"
+
+	"github.com/nandaryanizar/gopartial"
+)
+
+// ApplyPartial updates d from partial like gopartial.PartialUpdate with the "json" tag,
+// gopartial.SkipConditions and gopartial.AllUpdaters, without reflection.
+// Returns list of struct field names that was successfully updated.
+func (d *Customer) ApplyPartial(partial map[string]interface{}) ([]string, error) {
+	return d.applyPartial(partial, "", "")
+}
+
+// applyPartial implements ApplyPartial, prefixing the updated names and the failed paths
+func (d *Customer) applyPartial(partial map[string]interface{}, namePrefix string, pathPrefix string) ([]string, error) {
+	fieldsUpdated := make([]string, 0, len(partial))
+
+	if v, ok := partial["name"]; ok {
+		x, reason := gopartial.CoerceString(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Name", pathPrefix+"name", "name", &d.Name, v, reason)
+		}
+		d.Name = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Name")
+	}
+
+	if v, ok := partial["nickname"]; ok {
+		updated, updateSuccess, err := gopartial.UpdateField(&d.Nickname, v, "json", namePrefix+"Nickname", pathPrefix+"nickname")
+		if !updateSuccess {
+			err = gopartial.NewFieldError("Customer", "Nickname", pathPrefix+"nickname", "nickname", &d.Nickname, v, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		fieldsUpdated = append(fieldsUpdated, updated...)
+	}
+
+	if v, ok := partial["score"]; ok {
+		x, reason := gopartial.CoerceFloat(v, 64)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Score", pathPrefix+"score", "score", &d.Score, v, reason)
+		}
+		d.Score = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Score")
+	}
+
+	if v, ok := partial["ratio"]; ok {
+		if p, ok := v.(*float32); ok {
+			d.Ratio = p
+		} else if v == nil {
+			d.Ratio = nil
+		} else {
+			x, reason := gopartial.CoerceFloat(v, 32)
+			if reason != "" {
+				return nil, gopartial.NewFieldError("Customer", "Ratio", pathPrefix+"ratio", "ratio", &d.Ratio, v, reason)
+			}
+			y := float32(x)
+			d.Ratio = &y
+		}
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Ratio")
+	}
+
+	if v, ok := partial["age"]; ok {
+		x, reason := gopartial.CoerceInt(v, 8)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Age", pathPrefix+"age", "age", &d.Age, v, reason)
+		}
+		d.Age = int8(x)
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Age")
+	}
+
+	if v, ok := partial["rank"]; ok {
+		if v == nil {
+			var zero int
+			d.Rank = zero
+			fieldsUpdated = append(fieldsUpdated, namePrefix+"Rank")
+		} else {
+			x, reason := gopartial.CoerceInt(v, 0)
+			if reason != "" {
+				return nil, gopartial.NewFieldError("Customer", "Rank", pathPrefix+"rank", "rank", &d.Rank, v, reason)
+			}
+			d.Rank = int(x)
+			fieldsUpdated = append(fieldsUpdated, namePrefix+"Rank")
+		}
+	}
+
+	if v, ok := partial["visits"]; ok {
+		if p, ok := v.(*int); ok {
+			d.Visits = p
+		} else if v == nil {
+			d.Visits = nil
+		} else {
+			x, reason := gopartial.CoerceInt(v, 0)
+			if reason != "" {
+				return nil, gopartial.NewFieldError("Customer", "Visits", pathPrefix+"visits", "visits", &d.Visits, v, reason)
+			}
+			y := int(x)
+			d.Visits = &y
+		}
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Visits")
+	}
+
+	if v, ok := partial["credits"]; ok {
+		x, reason := gopartial.CoerceUint(v, 16)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Credits", pathPrefix+"credits", "credits", &d.Credits, v, reason)
+		}
+		d.Credits = uint16(x)
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Credits")
+	}
+
+	if v, ok := partial["active"]; ok {
+		x, reason := gopartial.CoerceBool(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Active", pathPrefix+"active", "active", &d.Active, v, reason)
+		}
+		d.Active = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Active")
+	}
+
+	if v, ok := partial["verified"]; ok {
+		if p, ok := v.(*bool); ok {
+			d.Verified = p
+		} else if v == nil {
+			d.Verified = nil
+		} else {
+			x, reason := gopartial.CoerceBool(v)
+			if reason != "" {
+				return nil, gopartial.NewFieldError("Customer", "Verified", pathPrefix+"verified", "verified", &d.Verified, v, reason)
+			}
+			d.Verified = &x
+		}
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Verified")
+	}
+
+	if v, ok := partial["birthday"]; ok {
+		x, reason := gopartial.CoerceTime(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Birthday", pathPrefix+"birthday", "birthday", &d.Birthday, v, reason)
+		}
+		d.Birthday = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Birthday")
+	}
+
+	if v, ok := partial["last_seen"]; ok {
+		if p, ok := v.(*time.Time); ok {
+			d.LastSeen = p
+		} else if v == nil {
+			d.LastSeen = nil
+		} else {
+			x, reason := gopartial.CoerceTime(v)
+			if reason != "" {
+				return nil, gopartial.NewFieldError("Customer", "LastSeen", pathPrefix+"last_seen", "last_seen", &d.LastSeen, v, reason)
+			}
+			d.LastSeen = &x
+		}
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"LastSeen")
+	}
+
+	if v, ok := partial["email"]; ok {
+		x, reason := gopartial.CoerceNullString(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Email", pathPrefix+"email", "email", &d.Email, v, reason)
+		}
+		d.Email = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Email")
+	}
+
+	if v, ok := partial["balance"]; ok {
+		x, reason := gopartial.CoerceNullFloat(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Balance", pathPrefix+"balance", "balance", &d.Balance, v, reason)
+		}
+		d.Balance = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Balance")
+	}
+
+	if v, ok := partial["orders"]; ok {
+		x, reason := gopartial.CoerceNullInt(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Orders", pathPrefix+"orders", "orders", &d.Orders, v, reason)
+		}
+		d.Orders = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Orders")
+	}
+
+	if v, ok := partial["premium"]; ok {
+		x, reason := gopartial.CoerceNullBool(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Premium", pathPrefix+"premium", "premium", &d.Premium, v, reason)
+		}
+		d.Premium = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Premium")
+	}
+
+	if v, ok := partial["joined"]; ok {
+		x, reason := gopartial.CoerceNullTime(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Customer", "Joined", pathPrefix+"joined", "joined", &d.Joined, v, reason)
+		}
+		d.Joined = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Joined")
+	}
+
+	if v, ok := partial["tags"]; ok {
+		if elements, ok := gopartial.Elements(v); ok {
+			s := make([]string, len(elements))
+			for i, e := range elements {
+				x, reason := gopartial.CoerceString(e)
+				if reason != "" {
+					return nil, gopartial.NewElementError(namePrefix+"Tags", pathPrefix+"tags", i, &s[i], e, reason)
+				}
+				s[i] = x
+			}
+			d.Tags = s
+			fieldsUpdated = append(fieldsUpdated, namePrefix+"Tags")
+		} else {
+			updated, updateSuccess, err := gopartial.UpdateField(&d.Tags, v, "json", namePrefix+"Tags", pathPrefix+"tags")
+			if !updateSuccess {
+				err = gopartial.NewFieldError("Customer", "Tags", pathPrefix+"tags", "tags", &d.Tags, v, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			fieldsUpdated = append(fieldsUpdated, updated...)
+		}
+	}
+
+	if v, ok := partial["lucky"]; ok {
+		if elements, ok := gopartial.Elements(v); ok {
+			s := make([]int, len(elements))
+			for i, e := range elements {
+				x, reason := gopartial.CoerceInt(e, 0)
+				if reason != "" {
+					return nil, gopartial.NewElementError(namePrefix+"Lucky", pathPrefix+"lucky", i, &s[i], e, reason)
+				}
+				s[i] = int(x)
+			}
+			d.Lucky = s
+			fieldsUpdated = append(fieldsUpdated, namePrefix+"Lucky")
+		} else {
+			updated, updateSuccess, err := gopartial.UpdateField(&d.Lucky, v, "json", namePrefix+"Lucky", pathPrefix+"lucky")
+			if !updateSuccess {
+				err = gopartial.NewFieldError("Customer", "Lucky", pathPrefix+"lucky", "lucky", &d.Lucky, v, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			fieldsUpdated = append(fieldsUpdated, updated...)
+		}
+	}
+
+	if v, ok := partial["extra"]; ok {
+		d.Extra = v
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Extra")
+	}
+
+	if v, ok := partial["meta"]; ok {
+		updated, updateSuccess, err := gopartial.UpdateField(&d.Meta, v, "json", namePrefix+"Meta", pathPrefix+"meta")
+		if !updateSuccess {
+			err = gopartial.NewFieldError("Customer", "Meta", pathPrefix+"meta", "meta", &d.Meta, v, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		fieldsUpdated = append(fieldsUpdated, updated...)
+	}
+
+	if v, ok := partial["address"]; ok {
+		if m, ok := v.(map[string]interface{}); ok {
+			nested, err := d.Address.applyPartial(m, namePrefix+"Address.", pathPrefix+"address.")
+			if err != nil {
+				return nil, err
+			}
+			fieldsUpdated = append(fieldsUpdated, nested...)
+		} else {
+			updated, updateSuccess, err := gopartial.UpdateField(&d.Address, v, "json", namePrefix+"Address", pathPrefix+"address")
+			if !updateSuccess {
+				err = gopartial.NewFieldError("Customer", "Address", pathPrefix+"address", "address", &d.Address, v, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			fieldsUpdated = append(fieldsUpdated, updated...)
+		}
+	}
+
+	if v, ok := partial["billing"]; ok {
+		if m, ok := v.(map[string]interface{}); ok {
+			if d.Billing == nil {
+				d.Billing = new(Address)
+			}
+			nested, err := d.Billing.applyPartial(m, namePrefix+"Billing.", pathPrefix+"billing.")
+			if err != nil {
+				return nil, err
+			}
+			fieldsUpdated = append(fieldsUpdated, nested...)
+		} else {
+			updated, updateSuccess, err := gopartial.UpdateField(&d.Billing, v, "json", namePrefix+"Billing", pathPrefix+"billing")
+			if !updateSuccess {
+				err = gopartial.NewFieldError("Customer", "Billing", pathPrefix+"billing", "billing", &d.Billing, v, err)
+			}
+			if err != nil {
+				return nil, err
+			}
+			fieldsUpdated = append(fieldsUpdated, updated...)
+		}
+	}
+
+	if v, ok := partial["shipping"]; ok {
+		updated, updateSuccess, err := gopartial.MergeField(&d.Shipping, v, "json", "city", namePrefix+"Shipping", pathPrefix+"shipping")
+		if !updateSuccess {
+			err = gopartial.NewFieldError("Customer", "Shipping", pathPrefix+"shipping", "shipping", &d.Shipping, v, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		fieldsUpdated = append(fieldsUpdated, updated...)
+	}
+
+	return fieldsUpdated, nil
+}
+
+// ApplyPartial updates d from partial like gopartial.PartialUpdate with the "json" tag,
+// gopartial.SkipConditions and gopartial.AllUpdaters, without reflection.
+// Returns list of struct field names that was successfully updated.
+func (d *Address) ApplyPartial(partial map[string]interface{}) ([]string, error) {
+	return d.applyPartial(partial, "", "")
+}
+
+// applyPartial implements ApplyPartial, prefixing the updated names and the failed paths
+func (d *Address) applyPartial(partial map[string]interface{}, namePrefix string, pathPrefix string) ([]string, error) {
+	fieldsUpdated := make([]string, 0, len(partial))
+
+	if v, ok := partial["city"]; ok {
+		x, reason := gopartial.CoerceString(v)
+		if reason != "" {
+			return nil, gopartial.NewFieldError("Address", "City", pathPrefix+"city", "city", &d.City, v, reason)
+		}
+		d.City = x
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"City")
+	}
+
+	if v, ok := partial["zip"]; ok {
+		if p, ok := v.(*int); ok {
+			d.Zip = p
+		} else if v == nil {
+			d.Zip = nil
+		} else {
+			x, reason := gopartial.CoerceInt(v, 0)
+			if reason != "" {
+				return nil, gopartial.NewFieldError("Address", "Zip", pathPrefix+"zip", "zip", &d.Zip, v, reason)
+			}
+			y := int(x)
+			d.Zip = &y
+		}
+		fieldsUpdated = append(fieldsUpdated, namePrefix+"Zip")
+	}
+
+	return fieldsUpdated, nil
+}
@@ -0,0 +1,128 @@
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nandaryanizar/gopartial"
+)
+
+func newCustomer() Customer {
+	zip := 12345
+	return Customer{
+		ID:       1,
+		Name:     "foo",
+		Rank:     7,
+		Tags:     []string{"a", "b"},
+		Birthday: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC),
+		Address:  Address{City: "Jakarta", Country: "ID", Zip: &zip},
+		Shipping: []Address{{City: "Jakarta", Country: "ID"}, {City: "Bogor"}},
+	}
+}
+
+// TestApplyPartial checks that the generated code behaves like the runtime library
+func TestApplyPartial(t *testing.T) {
+	var visits = 3
+	tests := []struct {
+		name    string
+		partial string
+		values  map[string]interface{}
+	}{
+		{name: "Basic types", partial: `{"name": "bar", "score": 1.5, "age": 30, "rank": 2, "credits": 7, "active": true}`},
+		{name: "Pointers", partial: `{"ratio": 0.5, "visits": 2, "verified": false, "last_seen": "2017-11-22T20:30:26.716Z"}`},
+		{name: "Null pointers", partial: `{"ratio": null, "visits": null, "verified": null, "last_seen": null, "billing": null}`},
+		{name: "Time from unix", partial: `{"birthday": 1511382626}`},
+		{name: "Time from string", partial: `{"birthday": "2017-11-22T20:30:26.716Z"}`},
+		{name: "Unparseable time", partial: `{"birthday": "yesterday"}`},
+		{name: "Null types", partial: `{"email": "a@b.c", "balance": 1.5, "orders": 2, "premium": true, "joined": "2017-11-22T20:30:26.716Z"}`},
+		{name: "Null types set to null", partial: `{"email": null, "balance": null, "orders": null, "premium": null, "joined": null}`},
+		{name: "Null types from strings", partial: `{"balance": "1.5", "orders": "3"}`},
+		{name: "Null types from empty strings", partial: `{"balance": "", "orders": ""}`},
+		{name: "Null time from unix", partial: `{"joined": 1.511382626e9}`},
+		{name: "Slices", partial: `{"tags": ["a", "b"], "lucky": [1, 2.5]}`},
+		{name: "Slice element rejected", partial: `{"tags": ["a", "b"], "lucky": [1, "x"]}`},
+		{name: "Null slice element", partial: `{"tags": ["a", null]}`},
+		{name: "Interface and map", partial: `{"extra": {"a": [1]}, "meta": {"b": true}}`},
+		{name: "Nested objects", partial: `{"address": {"city": "Bandung", "zip": 40111}, "billing": {"city": "Bogor"}}`},
+		{name: "Read only fields are skipped", partial: `{"id": 2, "address": {"country": "SG"}, "Untagged": "x"}`},
+		{name: "Int overflow", partial: `{"name": "bar", "age": 300}`},
+		{name: "Negative uint", partial: `{"credits": -1}`},
+		{name: "Type mismatch", partial: `{"active": "yes"}`},
+		{name: "Null not allowed", partial: `{"name": null}`},
+		{name: "Null interface", partial: `{"extra": null}`},
+		{name: "Null slices, maps and structs", partial: `{"tags": null, "lucky": null, "meta": null, "address": null}`},
+		{name: "Null as zero", partial: `{"rank": null, "age": 2}`},
+		{name: "Slice operators are objects without WithOperators", partial: `{"tags": {"$pull": "a", "$push": ["c"]}, "lucky": {"$addToSet": [3, 3]}}`},
+		{name: "Slice merged by key", partial: `{"shipping": [{"city": "Bogor", "zip": 16111}, {"city": "Depok"}, {"city": "Jakarta", "$patch": "delete"}]}`},
+		{name: "Slice merged by key error", partial: `{"shipping": [{"city": "Bogor"}, 1]}`},
+		{name: "Nested error", partial: `{"address": {"city": "Bandung", "zip": "x"}}`},
+		{name: "Pointer to string through the library", partial: `{"nickname": "f"}`},
+		{name: "Go values", values: map[string]interface{}{"visits": &visits, "age": int64(-5), "credits": uint8(3), "birthday": int64(0), "tags": []string{"c"}}},
+		{name: "Go unsigned null types", values: map[string]interface{}{"balance": uint(3), "orders": uint64(4), "joined": int64(1511382626)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partial := tt.values
+			if partial == nil {
+				require.NoError(t, json.Unmarshal([]byte(tt.partial), &partial))
+			}
+
+			want := newCustomer()
+			wantUpdated, wantErr := gopartial.PartialUpdate(&want, partial, "json", gopartial.SkipConditions, gopartial.AllUpdaters)
+
+			got := newCustomer()
+			gotUpdated, gotErr := got.ApplyPartial(partial)
+
+			require.Equal(t, wantErr, gotErr)
+			require.Equal(t, wantUpdated, gotUpdated)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+// TestApplyPartialSliceElement checks that a slice element that cannot be converted is an error
+func TestApplyPartialSliceElement(t *testing.T) {
+	customer := newCustomer()
+	_, err := customer.ApplyPartial(map[string]interface{}{"lucky": []interface{}{1.0, "x"}})
+
+	var fieldError *gopartial.FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "Lucky[1]", fieldError.Field)
+	require.Equal(t, "lucky[1]", fieldError.Path)
+	require.Equal(t, gopartial.ReasonTypeMismatch, fieldError.Reason)
+	require.Nil(t, customer.Lucky)
+}
+
+var benchmarkPartial = map[string]interface{}{
+	"name":     "bar",
+	"score":    1.5,
+	"age":      30.0,
+	"verified": true,
+	"birthday": "2017-11-22T20:30:26.716Z",
+	"email":    "a@b.c",
+	"address":  map[string]interface{}{"city": "Bandung"},
+}
+
+func BenchmarkApplyPartial(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		customer := newCustomer()
+		if _, err := customer.ApplyPartial(benchmarkPartial); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPartialUpdate(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		customer := newCustomer()
+		if _, err := gopartial.PartialUpdate(&customer, benchmarkPartial, "json", gopartial.SkipConditions, gopartial.AllUpdaters); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Command gopartial-gen generates reflection-free partial update methods.
+//
+// For every struct type given with -type it emits an ApplyPartial method
+//
+//	func (d *T) ApplyPartial(partial map[string]interface{}) ([]string, error)
+//
+// which behaves like gopartial.PartialUpdate(d, partial, tag, gopartial.SkipConditions, gopartial.AllUpdaters)
+// for plain and nested object keys, with the same coercion rules as the built-in updaters.
+// Fields of a type it cannot convert without reflection are handed to the runtime library.
+//
+// Typical use is a go:generate directive next to the types:
+//
+//	//go:generate gopartial-gen -type=User,Address
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of struct type names, required")
+	tagName := flag.String("tag", "json", "struct tag holding the partial keys, empty to use the field names")
+	output := flag.String("output", "", "output file name, default <dir>/<type>_partial.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: gopartial-gen -type=T[,T...] [-tag=json] [-output=file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	types := strings.Split(*typeNames, ",")
+	src, err := generate(dir, types, *tagName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gopartial-gen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(types[0])+"_partial.go")
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "gopartial-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
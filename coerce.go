@@ -0,0 +1,334 @@
+package gopartial
+
+import (
+	"database/sql"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// The Coerce functions convert a decoded partial value without reflection, following the rules
+// of the matching updater. They are used by the code generated by cmd/gopartial-gen and return
+// the reason the value was rejected, or an empty Reason on success.
+
+// numberKind is the family of a decoded number
+type numberKind int
+
+const (
+	notNumber numberKind = iota
+	nullNumber
+	signedNumber
+	unsignedNumber
+	floatNumber
+)
+
+// number returns the value of any Go number along with its family
+func number(v interface{}) (int64, uint64, float64, numberKind) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), 0, 0, signedNumber
+	case int8:
+		return int64(n), 0, 0, signedNumber
+	case int16:
+		return int64(n), 0, 0, signedNumber
+	case int32:
+		return int64(n), 0, 0, signedNumber
+	case int64:
+		return n, 0, 0, signedNumber
+	case uint:
+		return 0, uint64(n), 0, unsignedNumber
+	case uint8:
+		return 0, uint64(n), 0, unsignedNumber
+	case uint16:
+		return 0, uint64(n), 0, unsignedNumber
+	case uint32:
+		return 0, uint64(n), 0, unsignedNumber
+	case uint64:
+		return 0, n, 0, unsignedNumber
+	case float32:
+		return 0, 0, float64(n), floatNumber
+	case float64:
+		return 0, 0, n, floatNumber
+	case nil:
+		return 0, 0, 0, nullNumber
+	}
+	return 0, 0, 0, notNumber
+}
+
+// numberOf returns the value of a reflected number along with its family
+func numberOf(v reflect.Value) (int64, uint64, float64, numberKind) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), 0, 0, signedNumber
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0, v.Uint(), 0, unsignedNumber
+	case reflect.Float32, reflect.Float64:
+		return 0, 0, v.Float(), floatNumber
+	case reflect.Invalid:
+		return 0, 0, 0, nullNumber
+	}
+	return 0, 0, 0, notNumber
+}
+
+// mismatch returns the reason of a value that is not of the expected type
+func mismatch(v interface{}) Reason {
+	if v == nil {
+		return ReasonNullNotAllowed
+	}
+	return ReasonTypeMismatch
+}
+
+// mismatchOf returns the reason of a number family that cannot be converted
+func mismatchOf(kind numberKind) Reason {
+	if kind == nullNumber {
+		return ReasonNullNotAllowed
+	}
+	return ReasonTypeMismatch
+}
+
+// CoerceInt converts v to an int of bitSize bits (0 for int) like IntUpdater
+func CoerceInt(v interface{}, bitSize int) (int64, Reason) {
+	i, u, f, kind := number(v)
+	return toInt(i, u, f, kind, bitSize)
+}
+
+// CoerceUint converts v to an uint of bitSize bits (0 for uint) like UintUpdater
+func CoerceUint(v interface{}, bitSize int) (uint64, Reason) {
+	i, u, f, kind := number(v)
+	return toUint(i, u, f, kind, bitSize)
+}
+
+// CoerceFloat converts v to a float of bitSize bits like FloatUpdater
+func CoerceFloat(v interface{}, bitSize int) (float64, Reason) {
+	i, u, f, kind := number(v)
+	return toFloat(i, u, f, kind, bitSize)
+}
+
+// toInt converts a number, see number, to an int of bitSize bits
+func toInt(i int64, u uint64, f float64, kind numberKind, bitSize int) (int64, Reason) {
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	min, max := int64(-1)<<uint(bitSize-1), int64(1)<<uint(bitSize-1)-1
+
+	switch kind {
+	case signedNumber:
+		if i < min || i > max {
+			return 0, ReasonOverflow
+		}
+		return i, ""
+	case unsignedNumber:
+		if u > uint64(max) {
+			return 0, ReasonOverflow
+		}
+		return int64(u), ""
+	case floatNumber:
+		if f < math.MinInt64 || f >= math.MaxInt64 || int64(f) < min || int64(f) > max {
+			return 0, ReasonOverflow
+		}
+		return int64(f), ""
+	}
+	return 0, mismatchOf(kind)
+}
+
+// toUint converts a number, see number, to an uint of bitSize bits
+func toUint(i int64, u uint64, f float64, kind numberKind, bitSize int) (uint64, Reason) {
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	max := uint64(1)<<uint(bitSize-1)<<1 - 1
+
+	switch kind {
+	case signedNumber:
+		if i < 0 || uint64(i) > max {
+			return 0, ReasonOverflow
+		}
+		return uint64(i), ""
+	case unsignedNumber:
+		if u > max {
+			return 0, ReasonOverflow
+		}
+		return u, ""
+	case floatNumber:
+		if f < 0 || f >= math.MaxUint64 || uint64(f) > max {
+			return 0, ReasonOverflow
+		}
+		return uint64(f), ""
+	}
+	return 0, mismatchOf(kind)
+}
+
+// toFloat converts a number, see number, to a float of bitSize bits
+func toFloat(i int64, u uint64, f float64, kind numberKind, bitSize int) (float64, Reason) {
+	switch kind {
+	case signedNumber:
+		f = float64(i)
+	case unsignedNumber:
+		f = float64(u)
+	case floatNumber:
+	default:
+		return 0, mismatchOf(kind)
+	}
+
+	if bitSize == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+		return 0, ReasonOverflow
+	}
+	return f, ""
+}
+
+// CoerceString converts v to a string
+func CoerceString(v interface{}) (string, Reason) {
+	if s, ok := v.(string); ok {
+		return s, ""
+	}
+	return "", mismatch(v)
+}
+
+// CoerceBool converts v to a bool like BoolUpdater
+func CoerceBool(v interface{}) (bool, Reason) {
+	if b, ok := v.(bool); ok {
+		return b, ""
+	}
+	return false, mismatch(v)
+}
+
+// CoerceTime converts v to a time like TimeUpdater: an int64 or float64 unix time,
+// or a RFC 3339 string
+func CoerceTime(v interface{}) (time.Time, Reason) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, ""
+	case int64:
+		return time.Unix(t, 0), ""
+	case float64:
+		return time.Unix(int64(t), 0), ""
+	case string:
+		var parsed time.Time
+		if err := parsed.UnmarshalJSON([]byte(`"` + t + `"`)); err != nil {
+			return time.Time{}, ReasonUnparseableTime
+		}
+		return parsed, ""
+	}
+	return time.Time{}, mismatch(v)
+}
+
+// CoerceNullString converts v to a null.String like NullStringUpdater
+func CoerceNullString(v interface{}) (null.String, Reason) {
+	switch n := v.(type) {
+	case nil:
+		return null.String{}, ""
+	case null.String:
+		return n, ""
+	}
+	if s, ok := v.(string); ok {
+		return null.String{NullString: sql.NullString{Valid: true, String: s}}, ""
+	}
+	return null.String{}, ReasonTypeMismatch
+}
+
+// CoerceNullFloat converts v to a null.Float like NullFloatUpdater and NullTypeUpdater:
+// any number, or a numeric string, the empty string being null
+func CoerceNullFloat(v interface{}) (null.Float, Reason) {
+	switch n := v.(type) {
+	case null.Float:
+		return n, ""
+	case string:
+		parsed, ok := numericString(n)
+		if !ok {
+			return null.Float{}, ReasonTypeMismatch
+		}
+		v = parsed
+	}
+	if v == nil {
+		return null.Float{}, ""
+	}
+	f, reason := CoerceFloat(v, 64)
+	if reason != "" {
+		return null.Float{}, reason
+	}
+	return null.Float{NullFloat64: sql.NullFloat64{Valid: true, Float64: f}}, ""
+}
+
+// CoerceNullInt converts v to a null.Int like NullIntUpdater and NullTypeUpdater:
+// any number, or a numeric string, the empty string being null
+func CoerceNullInt(v interface{}) (null.Int, Reason) {
+	switch n := v.(type) {
+	case null.Int:
+		return n, ""
+	case string:
+		parsed, ok := numericString(n)
+		if !ok {
+			return null.Int{}, ReasonTypeMismatch
+		}
+		v = parsed
+	}
+	if v == nil {
+		return null.Int{}, ""
+	}
+	i, reason := CoerceInt(v, 64)
+	if reason != "" {
+		return null.Int{}, reason
+	}
+	return null.Int{NullInt64: sql.NullInt64{Valid: true, Int64: i}}, ""
+}
+
+// numericString returns the number of a numeric string like UpdateNullType,
+// nil for the empty string, or false when s is not a number
+func numericString(s string) (interface{}, bool) {
+	if s == "" {
+		return nil, true
+	}
+	n, ok := parseNumber(s)
+	if !ok {
+		return nil, false
+	}
+	return n.Interface(), true
+}
+
+// CoerceNullBool converts v to a null.Bool like NullBoolUpdater
+func CoerceNullBool(v interface{}) (null.Bool, Reason) {
+	switch n := v.(type) {
+	case nil:
+		return null.Bool{}, ""
+	case null.Bool:
+		return n, ""
+	}
+	if b, ok := v.(bool); ok {
+		return null.Bool{NullBool: sql.NullBool{Valid: true, Bool: b}}, ""
+	}
+	return null.Bool{}, ReasonTypeMismatch
+}
+
+// CoerceNullTime converts v to a null.Time like NullTimeUpdater and NullTypeUpdater:
+// a RFC 3339 string, or an int64 or float64 unix time
+func CoerceNullTime(v interface{}) (null.Time, Reason) {
+	switch n := v.(type) {
+	case nil:
+		return null.Time{}, ""
+	case null.Time:
+		return n, ""
+	}
+	if s, ok := v.(string); ok {
+		var t null.Time
+		if err := t.UnmarshalJSON([]byte(`"` + s + `"`)); err != nil {
+			return null.Time{}, ReasonUnparseableTime
+		}
+		return t, ""
+	}
+	// unix times are converted like TimeUpdater
+	switch v.(type) {
+	case int64, float64:
+		t, _ := CoerceTime(v)
+		return null.TimeFrom(t), ""
+	}
+	return null.Time{}, ReasonTypeMismatch
+}
+
+// Elements returns the elements of a decoded array, or false when v is not an array
+func Elements(v interface{}) ([]interface{}, bool) {
+	elements, ok := v.([]interface{})
+	return elements, ok
+}
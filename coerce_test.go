@@ -0,0 +1,49 @@
+package gopartial
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoerceNumbers(t *testing.T) {
+	tests := []struct {
+		name   string
+		coerce func() (interface{}, Reason)
+		want   interface{}
+		reason Reason
+	}{
+		{"Int8 max", func() (interface{}, Reason) { return CoerceInt(127, 8) }, int64(127), ""},
+		{"Int8 overflow", func() (interface{}, Reason) { return CoerceInt(128, 8) }, int64(0), ReasonOverflow},
+		{"Int8 min", func() (interface{}, Reason) { return CoerceInt(-128.9, 8) }, int64(-128), ""},
+		{"Int from huge uint", func() (interface{}, Reason) { return CoerceInt(uint64(math.MaxUint64), 64) }, int64(0), ReasonOverflow},
+		{"Int from huge float", func() (interface{}, Reason) { return CoerceInt(1e20, 64) }, int64(0), ReasonOverflow},
+		{"Int from string", func() (interface{}, Reason) { return CoerceInt("1", 0) }, int64(0), ReasonTypeMismatch},
+		{"Int from null", func() (interface{}, Reason) { return CoerceInt(nil, 0) }, int64(0), ReasonNullNotAllowed},
+		{"Uint8 max", func() (interface{}, Reason) { return CoerceUint(255.0, 8) }, uint64(255), ""},
+		{"Uint8 overflow", func() (interface{}, Reason) { return CoerceUint(256, 8) }, uint64(0), ReasonOverflow},
+		{"Uint64 max", func() (interface{}, Reason) { return CoerceUint(uint64(math.MaxUint64), 64) }, uint64(math.MaxUint64), ""},
+		{"Uint negative", func() (interface{}, Reason) { return CoerceUint(-1, 0) }, uint64(0), ReasonOverflow},
+		{"Float32 overflow", func() (interface{}, Reason) { return CoerceFloat(math.MaxFloat64, 32) }, float64(0), ReasonOverflow},
+		{"Float32 from int", func() (interface{}, Reason) { return CoerceFloat(int8(-3), 32) }, float64(-3), ""},
+		{"Float from bool", func() (interface{}, Reason) { return CoerceFloat(true, 64) }, float64(0), ReasonTypeMismatch},
+		{"Null int from string", func() (interface{}, Reason) { return CoerceNullInt("-2") }, null.IntFrom(-2), ""},
+		{"Null int from empty string", func() (interface{}, Reason) { return CoerceNullInt("") }, null.Int{}, ""},
+		{"Null int from huge string", func() (interface{}, Reason) { return CoerceNullInt("1e300") }, null.Int{}, ReasonOverflow},
+		{"Null int from huge float", func() (interface{}, Reason) { return CoerceNullInt(1e300) }, null.Int{}, ReasonOverflow},
+		{"Null int from huge uint", func() (interface{}, Reason) { return CoerceNullInt(uint64(math.MaxUint64)) }, null.Int{}, ReasonOverflow},
+		{"Null float from uint", func() (interface{}, Reason) { return CoerceNullFloat(uint(3)) }, null.FloatFrom(3), ""},
+		{"Null float from text", func() (interface{}, Reason) { return CoerceNullFloat("abc") }, null.Float{}, ReasonTypeMismatch},
+		{"Null time from unix", func() (interface{}, Reason) { return CoerceNullTime(int64(0)) }, null.TimeFrom(time.Unix(0, 0)), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := tt.coerce()
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
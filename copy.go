@@ -0,0 +1,247 @@
+package gopartial
+
+import (
+	"reflect"
+)
+
+// copied identifies a pointer, slice or map already copied: the address it refers to, its length
+// for a slice, and its type
+type copied struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// referenceOf returns the identity of the non nil pointer, slice or map v
+func referenceOf(v reflect.Value) copied {
+	if v.Kind() == reflect.Slice {
+		// slices sharing an array but not its length are different values
+		return copied{v.Pointer(), v.Len(), v.Type()}
+	}
+	return copied{v.Pointer(), 0, v.Type()}
+}
+
+// copier deep copies values, a pointer, slice or map reached twice is copied once
+// so that cycles and shared values keep their shape in the copy.
+// It remembers the original of each copy so that the copy can be committed back.
+type copier struct {
+	// copies holds the copy of each original
+	copies map[copied]reflect.Value
+	// originals holds the original of each copy
+	originals map[copied]reflect.Value
+	// committed holds the copies already committed
+	committed map[copied]bool
+	// root is the copy of the struct being staged, see stage
+	root        copied
+	rootReached bool
+}
+
+// newCopier returns an empty copier
+func newCopier() *copier {
+	return &copier{
+		copies:    make(map[copied]reflect.Value),
+		originals: make(map[copied]reflect.Value),
+		committed: make(map[copied]bool),
+	}
+}
+
+// deepCopy returns an addressable copy of v. Pointers, slices, maps and interfaces
+// reachable through exported fields are copied recursively so that updating the copy
+// never affects v. Unexported fields are copied as is.
+func deepCopy(v reflect.Value) reflect.Value {
+	return newCopier().copyOf(v)
+}
+
+// copyOf returns an addressable copy of v, see copyValue
+func (cp *copier) copyOf(v reflect.Value) reflect.Value {
+	dst := reflect.New(v.Type()).Elem()
+	cp.copyValue(dst, v)
+	return dst
+}
+
+// copyValue deep copies src into dst, dst must be settable
+func (cp *copier) copyValue(dst reflect.Value, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		key := referenceOf(src)
+		if newValue, ok := cp.copies[key]; ok {
+			cp.rootReached = cp.rootReached || referenceOf(newValue) == cp.root
+			dst.Set(newValue)
+			return
+		}
+		cp.copyReference(dst, src, key)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		dst.Set(cp.copyOf(src.Elem()))
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			cp.copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		// copies the unexported fields, exported ones are then copied deeply
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				cp.copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// copyReference copies the pointer, slice or map src into dst, recording the copy
+// before copying what src refers to
+func (cp *copier) copyReference(dst reflect.Value, src reflect.Value, key copied) {
+	var newValue reflect.Value
+	switch src.Kind() {
+	case reflect.Ptr:
+		newValue = reflect.New(src.Type().Elem())
+		cp.record(key, newValue, src)
+		cp.copyValue(newValue.Elem(), src.Elem())
+	case reflect.Slice:
+		newValue = reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		cp.record(key, newValue, src)
+		for i := 0; i < src.Len(); i++ {
+			cp.copyValue(newValue.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		newValue = reflect.MakeMapWithSize(src.Type(), src.Len())
+		cp.record(key, newValue, src)
+		iter := src.MapRange()
+		for iter.Next() {
+			newValue.SetMapIndex(iter.Key(), cp.copyOf(iter.Value()))
+		}
+	}
+	dst.Set(newValue)
+}
+
+// record remembers that newValue is the copy of original, whose identity is key
+func (cp *copier) record(key copied, newValue reflect.Value, original reflect.Value) {
+	cp.copies[key] = newValue
+	cp.originals[referenceOf(newValue)] = original
+}
+
+// stage returns a shallow copy of the struct value v where the fields at indexes are deep copied,
+// a pointer to v reached from these fields points to the copy
+func (cp *copier) stage(v reflect.Value, indexes []int) reflect.Value {
+	staged := reflect.New(v.Type())
+	staged.Elem().Set(v)
+	if v.CanAddr() {
+		cp.root = referenceOf(staged)
+		cp.record(referenceOf(v.Addr()), staged, v.Addr())
+		// the fields of v are committed by commitStaged
+		cp.committed[cp.root] = true
+	}
+	for _, i := range indexes {
+		cp.copyValue(staged.Elem().Field(i), v.Field(i))
+	}
+	return staged.Elem()
+}
+
+// commitStaged commits the fields at indexes of staged, see stage, back into v.
+// All the fields are committed when one of them points back to v since it may have been
+// updated through that pointer.
+func (cp *copier) commitStaged(v reflect.Value, staged reflect.Value, indexes []int) {
+	if cp.rootReached {
+		cp.commit(v, staged)
+		return
+	}
+	for _, i := range indexes {
+		cp.commit(v.Field(i), staged.Field(i))
+	}
+}
+
+// commit sets dst to the copy src, where the copied pointers, slices and maps are replaced
+// by their originals updated in place, so that the references held to the originals
+// see the changes. dst may be src itself.
+func (cp *copier) commit(dst reflect.Value, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		key := referenceOf(src)
+		target, ok := cp.originals[key]
+		if !ok {
+			// allocated by the update, it may still hold copies
+			target = src
+		}
+		if !cp.committed[key] {
+			cp.committed[key] = true
+			cp.commit(target.Elem(), src.Elem())
+		}
+		dst.Set(target)
+	case reflect.Slice, reflect.Map:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		key := referenceOf(src)
+		target, ok := cp.originals[key]
+		if !ok {
+			// made by the update, it may still hold copies
+			target = src
+		}
+		if !cp.committed[key] {
+			cp.committed[key] = true
+			cp.commitElements(target, src)
+		}
+		dst.Set(target)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		cp.commit(elem, src.Elem())
+		dst.Set(elem)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			cp.commit(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		// unexported fields are set as is, exported ones are then committed
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				cp.commit(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// commitElements commits the elements of the slice or map src into target,
+// the original of src or src itself
+func (cp *copier) commitElements(target reflect.Value, src reflect.Value) {
+	if src.Kind() == reflect.Slice {
+		for i := 0; i < src.Len(); i++ {
+			cp.commit(target.Index(i), src.Index(i))
+		}
+		return
+	}
+
+	if target.Pointer() != src.Pointer() {
+		for _, key := range target.MapKeys() {
+			if !src.MapIndex(key).IsValid() {
+				target.SetMapIndex(key, reflect.Value{})
+			}
+		}
+	}
+	iter := src.MapRange()
+	for iter.Next() {
+		elem := reflect.New(src.Type().Elem()).Elem()
+		cp.commit(elem, iter.Value())
+		target.SetMapIndex(iter.Key(), elem)
+	}
+}
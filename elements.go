@@ -0,0 +1,136 @@
+package gopartial
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// convertsElements reports whether v is converted element by element to a slice or map field of type t
+// with the updaters of the update, rather than assigned or handed to the registered updater or unmarshaler of t
+func (c *config) convertsElements(t reflect.Type, v reflect.Value) bool {
+	if !v.IsValid() || v.Type().AssignableTo(t) || c.registry.lookup(t) != nil || isUnmarshaler(reflect.PtrTo(t)) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		return isArray(v)
+	case reflect.Map:
+		return v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && t.Key().Kind() == reflect.String
+	}
+	return false
+}
+
+// isComposite reports whether val is an object or an array, the only values whose update reports
+// names and paths of its own, so that the names of scalar elements are built only for their errors
+func isComposite(val interface{}) bool {
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// isArray reports whether v is a slice or an array
+func isArray(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
+// updateElements sets the slice or map field to the elements of v, see updateSlice and updateMap
+func (c *config) updateElements(fieldValue reflect.Value, v reflect.Value, name string, path string) error {
+	if fieldValue.Kind() == reflect.Map {
+		return c.updateMap(fieldValue, v, name, path)
+	}
+	return c.updateSlice(fieldValue, v, name, path)
+}
+
+// updateSlice sets the slice field to the elements of v once they are all converted like fields,
+// so nested objects are applied to struct elements and null elements follow the null policy.
+// The field is unchanged when an element is rejected.
+func (c *config) updateSlice(fieldValue reflect.Value, v reflect.Value, name string, path string) error {
+	nval := reflect.MakeSlice(fieldValue.Type(), v.Len(), v.Len())
+	var fieldErrors FieldErrors
+	for i := 0; i < v.Len(); i++ {
+		elem := nval.Index(i)
+		element := elementAt(v, i, elem.Kind())
+		var elemName, elemPath string
+		if isComposite(element) {
+			elemName, elemPath = fmt.Sprintf("%v[%v]", name, i), fmt.Sprintf("%v[%v]", path, i)
+		}
+		_, updateSuccess, err := c.updateValue(nil, elem, element, elemName, elemPath)
+		if !updateSuccess {
+			err = newElementError(name, path, i, elem.Type(), element, err)
+		}
+		if err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	fieldValue.Set(nval)
+	return nil
+}
+
+// elementAt returns the element at index i of v, dereferenced when it points to the value of an element of kind,
+// e.g. &n for an int
+func elementAt(v reflect.Value, i int, kind reflect.Kind) interface{} {
+	element := v.Index(i)
+	if element.Kind() == reflect.Interface {
+		if element.Elem().Kind() != reflect.Ptr || kind == reflect.Ptr {
+			// the element of an []interface{} as it is, without boxing it again
+			return element.Interface()
+		}
+		element = element.Elem()
+	}
+	if element.Kind() == reflect.Ptr && kind != reflect.Ptr {
+		element = element.Elem()
+	}
+	if !element.IsValid() {
+		return nil
+	}
+	return element.Interface()
+}
+
+// updateMap sets the map field with string keys to the entries of the object v once they are all converted
+// like fields, in the order of the keys. The field is unchanged when an entry is rejected.
+func (c *config) updateMap(fieldValue reflect.Value, v reflect.Value, name string, path string) error {
+	typeOfMap := fieldValue.Type()
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	nval := reflect.MakeMapWithSize(typeOfMap, len(keys))
+	var fieldErrors FieldErrors
+	for _, key := range keys {
+		k := key.String()
+		val := v.MapIndex(key).Interface()
+		elem := reflect.New(typeOfMap.Elem()).Elem()
+		var elemName, elemPath string
+		if isComposite(val) {
+			elemName, elemPath = fmt.Sprintf("%v[%v]", name, k), joinPath(path, k)
+		}
+		_, updateSuccess, err := c.updateValue(nil, elem, val, elemName, elemPath)
+		if !updateSuccess {
+			fieldError := newFieldError(joinPath(path, k), k, typeOfMap.Elem(), val, err)
+			fieldError.Field = fmt.Sprintf("%v[%v]", name, k)
+			err = fieldError
+		}
+		if err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return err
+			}
+			continue
+		}
+		nval.SetMapIndex(key.Convert(typeOfMap.Key()), elem)
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	fieldValue.Set(nval)
+	return nil
+}
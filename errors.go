@@ -0,0 +1,320 @@
+package gopartial
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// ErrDestinationMustBeStructType is returned when dest does not point to a struct
+var ErrDestinationMustBeStructType = errors.New("Destination must be a struct type")
+
+// ErrDestinationMustBePointerType is returned when dest is not a pointer
+var ErrDestinationMustBePointerType = errors.New("Destination must be pointer to struct")
+
+// ErrPathNotFound is the cause of a FieldError whose path does not exist
+var ErrPathNotFound = errors.New("Path does not exist")
+
+// ErrReadOnly is the cause of a FieldError whose path targets a skipped field
+var ErrReadOnly = errors.New("Field is read only")
+
+// ErrMergeKeyNotFound is the cause of a FieldError whose merge key is not a field of the slice elements
+var ErrMergeKeyNotFound = errors.New("Merge key does not match a field of the elements")
+
+// ErrUnknownDirective is the cause of a FieldError whose directive is not DirectiveDelete
+var ErrUnknownDirective = errors.New("Unknown patch directive")
+
+// ErrUnknownOperator is the cause of a FieldError whose operator does not apply to the field
+var ErrUnknownOperator = errors.New("Unknown operator")
+
+// ErrPanic is the cause of the error of an update that recovered from a panic, e.g. in a custom updater
+var ErrPanic = errors.New("Recovered from a panic")
+
+// recovered returns the error of a panic recovered with value r
+func recovered(r interface{}) error {
+	return fmt.Errorf("%w: %v", ErrPanic, r)
+}
+
+// Reason is a machine-readable code telling why a field could not be updated.
+// It is also an error that updaters can return, or wrap, to tell why a value is rejected.
+type Reason string
+
+func (r Reason) Error() string {
+	return strings.Replace(string(r), "_", " ", -1)
+}
+
+// Reasons of a FieldError
+const (
+	// ReasonTypeMismatch is a value of a type that cannot be converted to the field type
+	ReasonTypeMismatch Reason = "type_mismatch"
+	// ReasonOverflow is a number that does not fit in the field type, e.g. 1000 for an int8 or -1 for a uint
+	ReasonOverflow Reason = "overflow"
+	// ReasonNullNotAllowed is a null value for a field that cannot be null
+	ReasonNullNotAllowed Reason = "null_not_allowed"
+	// ReasonUnparseableTime is a string that is not a valid time for a time field
+	ReasonUnparseableTime Reason = "unparseable_time"
+	// ReasonReadOnly is a path targeting a field matching a skip condition
+	ReasonReadOnly Reason = "readonly"
+	// ReasonUnknownPath is a path that does not exist
+	ReasonUnknownPath Reason = "unknown_path"
+	// ReasonTestFailed is a JSON Patch test operation that failed
+	ReasonTestFailed Reason = "test_failed"
+)
+
+// FieldError describes a partial value that cannot be applied to a field
+type FieldError struct {
+	// Path is the full path of the field made of the partial keys, e.g. address.city
+	Path string
+	// Struct is the name of the struct type holding the field
+	Struct string
+	// Field is the Go name of the field, followed by the index of a slice element, e.g. Tags[1]
+	Field string
+	// Key is the partial key of the field, or the index of a slice element
+	Key string
+	// Type is the type of the field, nil when the field does not exist
+	Type reflect.Type
+	// Kind is the kind of the rejected value, reflect.Invalid for null
+	Kind reflect.Kind
+	// Value is the rejected partial value
+	Value interface{}
+	// Reason tells why the value was rejected
+	Reason Reason
+	// Err is the cause when the field cannot be resolved, or the error of the updater
+	// that rejected the value when it is more than a Reason
+	Err error
+}
+
+// newFieldError returns the error of val rejected by a field of type fieldType because of cause,
+// the error of an updater. Without a Reason in cause, the reason is guessed from the field type and the value.
+func newFieldError(path string, key string, fieldType reflect.Type, val interface{}, cause error) *FieldError {
+	fieldError := &FieldError{
+		Path:   path,
+		Key:    key,
+		Type:   fieldType,
+		Kind:   reflect.ValueOf(val).Kind(),
+		Value:  val,
+		Reason: reasonOf(fieldType, val),
+	}
+
+	var reason Reason
+	if errors.As(cause, &reason) {
+		fieldError.Reason = reason
+	}
+	// a bare reason says it all
+	if _, ok := cause.(Reason); !ok {
+		fieldError.Err = cause
+	}
+	return fieldError
+}
+
+// NewFieldError returns the error of val rejected by a field of struct structName because of cause,
+// where field points to the rejected field. cause is nil or a Reason when the value was rejected
+// without an updater error. It is used by the code generated by cmd/gopartial-gen.
+func NewFieldError(structName string, fieldName string, path string, key string, field interface{}, val interface{}, cause error) *FieldError {
+	fieldError := newFieldError(path, key, reflect.TypeOf(field).Elem(), val, cause)
+	fieldError.Struct = structName
+	fieldError.Field = fieldName
+	return fieldError
+}
+
+// newElementError returns the error of val rejected by the element at index of the slice field name
+func newElementError(name string, path string, index int, elemType reflect.Type, val interface{}, cause error) *FieldError {
+	fieldError := newFieldError(fmt.Sprintf("%v[%v]", path, index), strconv.Itoa(index), elemType, val, cause)
+	fieldError.Field = fmt.Sprintf("%v[%v]", name, index)
+	return fieldError
+}
+
+// NewElementError returns the error of val rejected by the element at index of the slice field name,
+// where elem points to the rejected element. It is used by the code generated by cmd/gopartial-gen.
+func NewElementError(name string, path string, index int, elem interface{}, val interface{}, cause error) *FieldError {
+	return newElementError(name, path, index, reflect.TypeOf(elem).Elem(), val, cause)
+}
+
+// newPathError returns the error of a path that cannot be resolved because of err,
+// ErrPathNotFound or ErrReadOnly
+func newPathError(path string, key string, val interface{}, err error) *FieldError {
+	reason := ReasonUnknownPath
+	if err == ErrReadOnly {
+		reason = ReasonReadOnly
+	}
+	return &FieldError{
+		Path:   path,
+		Key:    key,
+		Kind:   reflect.ValueOf(val).Kind(),
+		Value:  val,
+		Reason: reason,
+		Err:    err,
+	}
+}
+
+func (e *FieldError) Error() string {
+	// the field does not exist
+	if e.Err != nil && e.Type == nil {
+		return fmt.Sprintf("%v: %v", e.Path, e.Err)
+	}
+
+	name := e.Path
+	if e.Struct != "" && e.Field != "" {
+		name = e.Struct + "." + e.Field
+	}
+	if e.Reason == ReasonTestFailed {
+		return fmt.Sprintf("Test operation failed at path %v", name)
+	}
+	message := fmt.Sprintf("%v cannot be assigned with value %v", name, e.Value)
+	if e.Value == nil {
+		message = fmt.Sprintf("%v cannot be assigned with value null", name)
+	}
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+	return message
+}
+
+// Unwrap returns the cause of the error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// reasonOf guesses why val was rejected by a field of type fieldType
+func reasonOf(fieldType reflect.Type, val interface{}) Reason {
+	v := reflect.ValueOf(val)
+	if !v.IsValid() {
+		return ReasonNullNotAllowed
+	}
+
+	if isNumberKind(v.Kind()) && isNumberType(fieldType) {
+		return ReasonOverflow
+	}
+
+	if v.Kind() == reflect.String && isTimeType(fieldType) {
+		return ReasonUnparseableTime
+	}
+
+	return ReasonTypeMismatch
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isNumberType reports whether t is a number, a pointer to a number or a nullable number
+func isNumberType(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	t = valueType(t)
+	switch t {
+	case reflect.TypeOf(null.Int{}), reflect.TypeOf(null.Float{}):
+		return true
+	}
+	return isNumberKind(t.Kind())
+}
+
+// isIntegerType reports whether t is an integer, a pointer to an integer or a nullable integer
+func isIntegerType(t reflect.Type) bool {
+	switch valueType(t).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// valueType returns the type of the value held by a field of type t: the type pointed to,
+// or the type wrapped by a nullable type
+func valueType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isNullWrapper(t) {
+		t = t.Field(0).Type
+	}
+	if isSQLNull(t) {
+		t = t.Field(0).Type
+	}
+	return t
+}
+
+// isTimeType reports whether t is a time, a pointer to a time or a nullable time
+func isTimeType(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch valueType(t) {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(null.Time{}):
+		return true
+	}
+	return false
+}
+
+// FieldErrors is the list of every field that failed during an update
+// that collects errors instead of stopping on the first one
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether one of the field errors matches target, so that errors.Is
+// looks into the field errors before Go 1.20 too
+func (e FieldErrors) Is(target error) bool {
+	for _, fieldError := range e {
+		if errors.Is(fieldError, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As sets target to the first of the field errors matching it, so that errors.As
+// can match a single *FieldError before Go 1.20 too
+func (e FieldErrors) As(target interface{}) bool {
+	for _, fieldError := range e {
+		if errors.As(fieldError, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the field errors so that errors.As can match a single *FieldError
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fieldError := range e {
+		errs[i] = fieldError
+	}
+	return errs
+}
+
+// collect records err, a *FieldError or FieldErrors, when the update collects errors.
+// It returns err when the update must stop instead.
+func (c *config) collect(fieldErrors *FieldErrors, err error) error {
+	if !c.collectErrors {
+		return err
+	}
+
+	switch e := err.(type) {
+	case *FieldError:
+		*fieldErrors = append(*fieldErrors, e)
+	case FieldErrors:
+		*fieldErrors = append(*fieldErrors, e...)
+	default:
+		return err
+	}
+	return nil
+}
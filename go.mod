@@ -1,8 +1,14 @@
 module github.com/nandaryanizar/gopartial
 
-go 1.14
+go 1.18
 
 require (
 	github.com/guregu/null v4.0.0+incompatible
 	github.com/stretchr/testify v1.6.1
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
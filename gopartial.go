@@ -1,13 +1,56 @@
 package gopartial
 
 import (
-	"errors"
-	"fmt"
 	"reflect"
 )
 
-var errDestinationMustBeStructType = errors.New("Destination must be a struct type")
-var errDestinationMustBePointerType = errors.New("Destination must be pointer to struct")
+// config holds the settings shared by every field visited during one update
+type config struct {
+	tagName        string
+	skipConditions []func(reflect.StructField) bool
+	updaters       []func(reflect.Value, reflect.Value) bool
+	// checkedUpdaters are tried before updaters and tell why a value is rejected
+	checkedUpdaters []Updater
+	// null is what null does to the fields that cannot be nil, NullNotAllowed when 0
+	null NullPolicy
+	// mergeKey matches the objects of an array to the elements of the slice of structs field being updated,
+	// set for that field only
+	mergeKey string
+	// operators evaluates the field operators of the objects against the current field values
+	operators bool
+	// mergePatch applies RFC 7396 semantics instead of a plain partial update
+	mergePatch bool
+	// atomic leaves dest unchanged when any field fails
+	atomic bool
+	// collectErrors keeps going after a field fails and reports every failed field
+	collectErrors bool
+	// registry holds the updaters looked up by field type before the ordered updaters
+	registry *Registry
+	// strict rejects the keys that do not match a settable field
+	strict bool
+	// caseInsensitive matches the keys to the fields regardless of case
+	caseInsensitive bool
+	// plans is the plan cache of a Patcher, nil for the package-level cache
+	plans *planCache
+	// cachedSkips evaluates the skip conditions once per field when building the plans,
+	// only for a Patcher whose skip conditions never change
+	cachedSkips bool
+}
+
+// Result is the outcome of Apply
+type Result struct {
+	// Updated is the list of struct field names that was successfully updated
+	Updated []string
+}
+
+// Apply updates the struct pointed to by dest from a map[string]interface{} where the partial keys
+// are looked up in the "json" tag of the fields, skipping the fields matching SkipConditions and
+// converting values through Updaters. Options change those defaults and the update mode.
+// Passing a value instead of a pointer is a compile error, dest must still point to a struct.
+func Apply[T any](dest *T, partial map[string]interface{}, opts ...Option) (Result, error) {
+	fieldsUpdated, err := newConfig(opts...).update(dest, partial)
+	return Result{Updated: fieldsUpdated}, err
+}
 
 // PartialUpdate updates destination object (Must be a pointer to a struct)
 // from a map[string]interface{} where struct tag name is equals to the map key.
@@ -15,87 +58,448 @@ var errDestinationMustBePointerType = errors.New("Destination must be pointer to
 // destination Value and the to be assigned Value and return true if updates is successful
 // Returns list of struct field names that was successfully updated.
 // If tagName is not provided, the default lookup value would be the field's name.
+// Keys can also address nested values, either dotted (address.city) or as a JSON Pointer (/items/2/qty).
 func PartialUpdate(dest interface{}, partial map[string]interface{}, tagName string, skipConditions []func(reflect.StructField) bool, updaters []func(reflect.Value, reflect.Value) bool) ([]string, error) {
+	return newConfig(positional(tagName, skipConditions, updaters)...).update(dest, partial)
+}
+
+// AtomicPartialUpdate works like PartialUpdate except that the update is all or nothing:
+// either every field of partial is applied or dest is left exactly as it was.
+// The fields of partial are updated in a copy which is written back to dest once every field succeeded,
+// nested pointers, slices and maps of dest are updated in place so references held into dest stay live.
+func AtomicPartialUpdate(dest interface{}, partial map[string]interface{}, tagName string, skipConditions []func(reflect.StructField) bool, updaters []func(reflect.Value, reflect.Value) bool) ([]string, error) {
+	return newConfig(append(positional(tagName, skipConditions, updaters), WithAtomic())...).update(dest, partial)
+}
+
+// ExhaustivePartialUpdate works like PartialUpdate except that it does not stop on the first field
+// that fails. Every other field is still applied, and the returned error is a FieldErrors listing
+// every failed field, along with the list of struct field names that was successfully updated.
+func ExhaustivePartialUpdate(dest interface{}, partial map[string]interface{}, tagName string, skipConditions []func(reflect.StructField) bool, updaters []func(reflect.Value, reflect.Value) bool) ([]string, error) {
+	return newConfig(append(positional(tagName, skipConditions, updaters), WithCollectErrors())...).update(dest, partial)
+}
+
+// UpdateField applies val to the struct field pointed to by field the way PartialUpdate does with
+// SkipConditions and AllUpdaters. name and path are the full name and the key path of the field.
+// It is used by the code generated by cmd/gopartial-gen for the fields it cannot convert without reflection.
+// Returns the names of what was updated, false and the error of the updater when val cannot be assigned,
+// or true and the errors of nested fields.
+func UpdateField(field interface{}, val interface{}, tagName string, name string, path string) ([]string, bool, error) {
+	return fieldConfig(tagName).updateValue(nil, reflect.ValueOf(field).Elem(), val, name, path)
+}
+
+// MergeField is UpdateField for a slice of structs field whose patch tag has a merge key, e.g. `patch:"mergeKey=id"`:
+// the objects of an array are merged into the elements matched by mergeKey.
+// It is used by the code generated by cmd/gopartial-gen.
+func MergeField(field interface{}, val interface{}, tagName string, mergeKey string, name string, path string) ([]string, bool, error) {
+	c := fieldConfig(tagName)
+	c.mergeKey = mergeKey
+	return c.updateValue(nil, reflect.ValueOf(field).Elem(), val, name, path)
+}
+
+// fieldConfig returns the config of the fields updated by the generated code
+func fieldConfig(tagName string) *config {
+	return &config{
+		tagName:        tagName,
+		skipConditions: SkipConditions,
+		updaters:       AllUpdaters,
+	}
+}
+
+// update validates dest and applies partial to it
+func (c *config) update(dest interface{}, partial map[string]interface{}) (fieldsUpdated []string, err error) {
+	// the panics of a field are errors of the field, this only catches the others
+	defer func() {
+		if r := recover(); r != nil {
+			fieldsUpdated, err = nil, recovered(r)
+		}
+	}()
+
+	valueOfDest, err := structValue(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.atomic {
+		return c.updateStruct(valueOfDest, partial, "", "")
+	}
+
+	// the fields of partial are updated in a copy which is committed to dest only when every field succeeded
+	indexes := c.stagedFields(valueOfDest, partial)
+	cp := newCopier()
+	document := cp.stage(valueOfDest, indexes)
+	fieldsUpdated, err = c.updateStruct(document, partial, "", "")
+	if err != nil {
+		return nil, err
+	}
+	cp.commitStaged(valueOfDest, document, indexes)
+	return fieldsUpdated, nil
+}
+
+// stagedFields returns the indexes of the fields of valueOfDest that partial may update,
+// either by key or as the first token of a path key
+func (c *config) stagedFields(valueOfDest reflect.Value, partial map[string]interface{}) []int {
+	indexes := make([]int, 0, len(partial))
+	fields := c.planFor(valueOfDest.Type()).fields
+	for i := range fields {
+		if _, _, ok := c.lookup(partial, fields[i].key); ok {
+			indexes = append(indexes, fields[i].index)
+		}
+	}
+	for key := range partial {
+		if !isPathKey(key) {
+			continue
+		}
+		tokens, err := pathTokens(key)
+		if err != nil || len(tokens) == 0 {
+			continue
+		}
+		if field, err := c.fieldByKey(valueOfDest, tokens[0]); err == nil && !containsIndex(indexes, field.index) {
+			indexes = append(indexes, field.index)
+		}
+	}
+	return indexes
+}
+
+// containsIndex reports whether indexes contains index
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// structValue returns the struct value pointed to by dest
+func structValue(dest interface{}) (reflect.Value, error) {
 	valueOfDest := reflect.ValueOf(dest)
 	// Must be a pointer to a struct so that it can be updated
 	if valueOfDest.Kind() != reflect.Ptr {
-		return nil, errDestinationMustBePointerType
+		return reflect.Value{}, ErrDestinationMustBePointerType
 	}
 	valueOfDest = valueOfDest.Elem()
 
-	typeOfDest := valueOfDest.Type()
 	// Must be a pointer to a struct so that it can be updated
-	if typeOfDest.Kind() != reflect.Struct {
-		return nil, errDestinationMustBeStructType
+	if valueOfDest.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrDestinationMustBeStructType
 	}
 
+	return valueOfDest, nil
+}
+
+// updateStruct applies partial to the struct value valueOfDest, recursing into nested
+// struct and pointer to struct fields when the partial value is itself an object.
+// name and path are the field name and the key path of valueOfDest itself, empty for dest,
+// they prefix the names of the updated fields and the paths of the failed ones.
+func (c *config) updateStruct(valueOfDest reflect.Value, partial map[string]interface{}, name string, path string) ([]string, error) {
+	typeOfDest := valueOfDest.Type()
+
 	// fieldsUpdated is to keep track all the field names that were successfuly updated
-	fieldsUpdated := make([]string, 0)
+	fieldsUpdated := make([]string, 0, len(partial))
+	var fieldErrors FieldErrors
 
-	for i := 0; i < typeOfDest.NumField(); i++ {
-		field := typeOfDest.Field(i)
+	if c.strict {
+		if err := c.checkKeys(valueOfDest, partial, path); err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-		// skip this field if it cant be set
-		if !valueOfDest.Field(i).CanSet() {
+	fields := c.planFor(typeOfDest).fields
+	for i := range fields {
+		field := &fields[i]
+
+		// get the partial value based on the tagName
+		key, val, ok := c.lookup(partial, field.key)
+		if !ok || c.skipField(field) {
 			continue
 		}
 
-		skip := false
-		// go through all extended skip conditions
-		for _, skipCondition := range skipConditions {
-			skip = skipCondition(field)
-			if skip {
-				// break on the first skip condition found
-				break
+		fc := c
+		if val == nil {
+			fc = c.forNull(field)
+		} else if field.mergeKey != c.mergeKey {
+			fc = c.forMergeKey(field)
+		}
+		var updateSuccess bool
+		var err error
+		fieldsUpdated, updateSuccess, err = fc.updateValue(fieldsUpdated, valueOfDest.Field(field.index), val, joinPath(name, field.field.Name), joinPath(path, key))
+		if !updateSuccess {
+			fieldError := newFieldError(joinPath(path, key), key, field.field.Type, val, err)
+			fieldError.Struct = typeOfDest.Name()
+			fieldError.Field = field.field.Name
+			err = fieldError
+		}
+		if err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return nil, err
 			}
 		}
-		if skip {
-			continue
+	}
+
+	// keys addressing nested values, e.g. address.city or /items/2/qty
+	pathsUpdated, err := c.updatePaths(valueOfDest, partial, name, path)
+	fieldsUpdated = append(fieldsUpdated, pathsUpdated...)
+	if err != nil {
+		if err := c.collect(&fieldErrors, err); err != nil {
+			return nil, err
 		}
+	}
 
-		fieldName := field.Name
-		if tagName != "" {
-			fieldName = field.Tag.Get(tagName)
+	if len(fieldErrors) > 0 {
+		return fieldsUpdated, fieldErrors
+	}
+	return fieldsUpdated, nil
+}
+
+// skip reports whether any of the skip conditions matches the field
+func (c *config) skip(field reflect.StructField) bool {
+	// go through all extended skip conditions
+	for _, skipCondition := range c.skipConditions {
+		if skipCondition(field) {
+			// stop on the first skip condition found
+			return true
 		}
+	}
+	return false
+}
 
-		// get the partial value based on the tagName
-		if val, ok := partial[fieldName]; ok {
-			v := reflect.ValueOf(val)
-			updateSuccess := false
-
-			if valueOfDest.Field(i).Kind() == reflect.Slice {
-				updateSuccess = SliceUpdater(valueOfDest.Field(i), v)
-				if updateSuccess {
-					updateSuccess = true
-				}
-			} else if valueOfDest.Field(i).Kind() == v.Kind() {
-				valueOfDest.Field(i).Set(v)
-				updateSuccess = true
-			} else {
-				// go through all extended process types
-				for _, updater := range updaters {
-					updateSuccess = updater(valueOfDest.Field(i), v)
-					if updateSuccess {
-						// the first updateSuccess found, break the loop
-						break
-					}
-				}
+// skipField reports whether a field of a plan matches any of the skip conditions
+func (c *config) skipField(field *fieldPlan) bool {
+	if c.cachedSkips {
+		return field.skipped
+	}
+	return c.skip(field.field)
+}
+
+// key returns the partial key of the field, its tag value
+// or the field's name if tagName is not provided
+func (c *config) key(field reflect.StructField) string {
+	if c.tagName != "" {
+		return field.Tag.Get(c.tagName)
+	}
+	return field.Name
+}
+
+// updateValue updates fieldValue with val and appends the names of what was updated to fieldsUpdated:
+// name itself, or the nested field names prefixed by name when val is a nested object.
+// It returns false and the error of the updater when val cannot be assigned to fieldValue,
+// or true and the errors of the nested fields or of the slice elements.
+func (c *config) updateValue(fieldsUpdated []string, fieldValue reflect.Value, val interface{}, name string, path string) (updated []string, updateSuccess bool, err error) {
+	// partial values are untrusted and updaters may be custom: a panic rejects val with ErrPanic
+	defer func() {
+		if r := recover(); r != nil {
+			updated, updateSuccess, err = fieldsUpdated, false, recovered(r)
+		}
+	}()
+
+	// with WithOperators, objects of operators edit the fields instead of replacing them
+	if operators, ok := val.(map[string]interface{}); ok && isOperators(operators) {
+		if handled, updated, err := c.updateOperators(fieldValue, operators, name, path); handled {
+			if err != nil || !updated {
+				return fieldsUpdated, true, err
 			}
+			return appendName(fieldsUpdated, name), true, nil
+		}
+	}
 
-			if updateSuccess {
-				fieldsUpdated = append(fieldsUpdated, field.Name)
-			} else {
-				if !v.IsValid() {
-					errMsg := fmt.Sprintf("%v.%v cannot be assigned with value null", typeOfDest.Name(), field.Name)
-					return nil, errors.New(errMsg)
-				} else {
-					errMsg := fmt.Sprintf("%v.%v cannot be assigned with value %v", typeOfDest.Name(), field.Name, v.Interface())
-					return nil, errors.New(errMsg)
-				}
+	// a nested object is applied partially to a struct or pointer to struct field,
+	// unless an updater is registered for the field type
+	if nested, ok := val.(map[string]interface{}); ok && isNestedStruct(fieldValue.Type()) && c.registry.lookup(fieldValue.Type()) == nil {
+		nestedFieldsUpdated, err := c.updateNested(fieldValue, nested, name, path)
+		return append(fieldsUpdated, nestedFieldsUpdated...), true, err
+	}
+
+	// arrays are merged by key into a slice of structs whose patch tag has a merge key
+	if v := reflect.ValueOf(val); c.mergesByKey(fieldValue.Type(), v) {
+		if updateSuccess, err := c.mergeSlice(fieldValue, v, name, path); !updateSuccess || err != nil {
+			return fieldsUpdated, updateSuccess, err
+		}
+		return appendName(fieldsUpdated, name), true, nil
+	}
+
+	if c.mergePatch {
+		if handled, updateSuccess, err := c.mergeValue(fieldValue, val, name, path); handled {
+			if !updateSuccess {
+				return fieldsUpdated, false, err
 			}
+			return appendName(fieldsUpdated, name), true, err
 		}
+	}
 
+	// arrays and objects are converted element by element so that a rejected element is reported at its index or key
+	if v := reflect.ValueOf(val); c.convertsElements(fieldValue.Type(), v) {
+		if err := c.updateElements(fieldValue, v, name, path); err != nil {
+			return fieldsUpdated, true, err
+		}
+		return appendName(fieldsUpdated, name), true, nil
 	}
 
-	return fieldsUpdated, nil
+	if updateSuccess, err := c.assign(fieldValue, reflect.ValueOf(val)); !updateSuccess {
+		return fieldsUpdated, false, err
+	}
+	return appendName(fieldsUpdated, name), true, nil
+}
+
+// appendName appends name to fieldsUpdated, the scalar elements of slices and maps have no name
+// since they are not reported
+func appendName(fieldsUpdated []string, name string) []string {
+	if name == "" {
+		return fieldsUpdated
+	}
+	return append(fieldsUpdated, name)
+}
+
+// assign sets v to fieldValue, through the updater registered for the field type, the element
+// of a pointer, the configured updaters then the unmarshaler of the field type, directly when the kinds match,
+// or through the slice updater and the configured updaters.
+// It returns false and the error of the updater that rejected v, if any, when v cannot be assigned.
+func (c *config) assign(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	if updater := c.registry.lookup(fieldValue.Type()); updater != nil {
+		if handled, err := updater.Update(fieldValue, v); handled {
+			return err == nil, err
+		}
+	}
+
+	// null resets the fields that can be nil
+	if !v.IsValid() && canBeNil(fieldValue.Kind()) {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, nil
+	}
+
+	assignable := v.IsValid() && v.Type().AssignableTo(fieldValue.Type())
+
+	// the updaters still apply when the element of a pointer rejects v,
+	// the error is kept for when none of them does
+	var rejectErr error
+	if fieldValue.Kind() == reflect.Ptr && !assignable {
+		updateSuccess, err := c.assignPointer(fieldValue, v)
+		if updateSuccess {
+			return true, nil
+		}
+		rejectErr = err
+	} else if !assignable && !convertedByUpdaters(fieldValue.Type()) && isUnmarshaler(reflect.PtrTo(fieldValue.Type())) {
+		// the updaters given by the caller come before the unmarshaler of the field type
+		if handled, err := c.applyUpdaters(fieldValue, v); handled {
+			return err == nil, err
+		}
+		handled, err := UpdateUnmarshaler(fieldValue, v)
+		if handled && err == nil {
+			return true, nil
+		}
+		return c.assignNull(fieldValue, v, err)
+	}
+
+	if fieldValue.Kind() == reflect.Slice {
+		if isArray(v) && c.updateSlice(fieldValue, v, "", "") == nil {
+			return true, nil
+		}
+		return false, rejectErr
+	} else if fieldValue.Kind() == v.Kind() {
+		// a value rejected by the element of a pointer is not set as it is
+		if rejectErr != nil {
+			return false, rejectErr
+		}
+		if setConverted(fieldValue, v) {
+			return true, nil
+		}
+	} else if fieldValue.Kind() == reflect.Interface && v.IsValid() && v.Type().AssignableTo(fieldValue.Type()) {
+		// interface fields accept any value implementing them
+		fieldValue.Set(v)
+		return true, nil
+	}
+
+	if handled, err := c.applyUpdaters(fieldValue, v); handled {
+		return err == nil, err
+	}
+	return c.assignNull(fieldValue, v, rejectErr)
+}
+
+// applyUpdaters updates fieldValue through the first of the checked updaters, then of the updaters,
+// handling v. handled is false when none of them does.
+func (c *config) applyUpdaters(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	// the first updater handling v stops the loop
+	for _, updater := range c.checkedUpdaters {
+		if handled, err := updater.Update(fieldValue, v); handled {
+			return true, err
+		}
+	}
+	// go through all extended process types
+	for _, updater := range c.updaters {
+		if updater(fieldValue, v) {
+			// the first updateSuccess found, break the loop
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// assignNull resets fieldValue when v is a null that no updater handles and the null policy allows it,
+// and returns rejectErr otherwise
+func (c *config) assignNull(fieldValue reflect.Value, v reflect.Value, rejectErr error) (bool, error) {
+	if !v.IsValid() && c.null == NullAsZero {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, nil
+	}
+	return false, rejectErr
+}
+
+// setConverted sets v to fieldValue when v is assignable to the field type, or of the same kind
+// and convertible to it, e.g. a string to a field of type Status string. It returns false otherwise.
+func setConverted(fieldValue reflect.Value, v reflect.Value) bool {
+	switch {
+	case !v.IsValid():
+		return false
+	case v.Type().AssignableTo(fieldValue.Type()):
+		fieldValue.Set(v)
+	case v.Kind() == fieldValue.Kind() && v.Type().ConvertibleTo(fieldValue.Type()):
+		fieldValue.Set(v.Convert(fieldValue.Type()))
+	default:
+		return false
+	}
+	return true
+}
+
+// assignPointer sets a pointer field to nil when v is null, or else to a new element assigned with v
+// the same way as a field of the element type. v is dereferenced when it is a pointer itself.
+// It returns false and the error of the updater that rejected v, if any, when v cannot be assigned.
+func (c *config) assignPointer(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() == reflect.Ptr {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, nil
+	}
+
+	elem := reflect.New(fieldValue.Type().Elem())
+	if updateSuccess, err := c.assign(elem.Elem(), v); !updateSuccess {
+		return false, err
+	}
+	fieldValue.Set(elem)
+	return true, nil
+}
+
+// isNestedStruct reports whether t is a struct or a pointer to a struct
+// that can be partially updated from a nested object. Structs with an unmarshaler
+// decode objects themselves, see UpdateUnmarshaler.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isUnmarshaler(reflect.PtrTo(t))
+}
+
+// updateNested partially updates a struct or pointer to struct field,
+// allocating a new struct when the pointer is nil
+func (c *config) updateNested(fieldValue reflect.Value, partial map[string]interface{}, name string, path string) ([]string, error) {
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	return c.updateStruct(fieldValue, partial, name, path)
 }
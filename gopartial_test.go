@@ -1,13 +1,20 @@
 package gopartial
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/guregu/null"
+	"github.com/guregu/null/zero"
 )
 
 type sub struct {
@@ -1312,6 +1319,115 @@ func TestPartialUpdate(t *testing.T) {
 			want:    []string{"Field16"},
 			wantErr: false,
 		},
+
+		// nested struct
+		test{
+			name: "Update field11 (sub) with nested object",
+			args: args{
+				dest: &destination{},
+				partial: map[string]interface{}{
+					"field11": map[string]interface{}{
+						"fielda": str,
+					},
+				},
+				tagName:        "json",
+				updaters:       Updaters,
+				skipConditions: SkipConditions,
+			},
+			want:    []string{"Field11.FieldA"},
+			wantErr: false,
+		},
+		test{
+			name: "Update field11p (*sub) with nested object",
+			args: args{
+				dest: &destination{},
+				partial: map[string]interface{}{
+					"field11p": map[string]interface{}{
+						"fielda": str,
+						"fieldb": str,
+					},
+				},
+				tagName:        "json",
+				updaters:       Updaters,
+				skipConditions: SkipConditions,
+			},
+			want:    []string{"Field11p.FieldA", "Field11p.FieldB"},
+			wantErr: false,
+		},
+		test{
+			name: "Update field11 (sub) with nested object of wrong type",
+			args: args{
+				dest: &destination{},
+				partial: map[string]interface{}{
+					"field11": map[string]interface{}{
+						"fielda": i,
+					},
+				},
+				tagName:        "json",
+				updaters:       Updaters,
+				skipConditions: SkipConditions,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+
+		// path keys
+		test{
+			name: "Update nested field with dotted key",
+			args: args{
+				dest: &destination{},
+				partial: map[string]interface{}{
+					"field11.fielda": str,
+				},
+				tagName:        "json",
+				updaters:       Updaters,
+				skipConditions: SkipConditions,
+			},
+			want:    []string{"Field11.FieldA"},
+			wantErr: false,
+		},
+		test{
+			name: "Update nested field with JSON pointer key",
+			args: args{
+				dest: &destination{},
+				partial: map[string]interface{}{
+					"/field11p/fieldb": str,
+				},
+				tagName:        "json",
+				updaters:       Updaters,
+				skipConditions: SkipConditions,
+			},
+			want:    []string{"Field11p.FieldB"},
+			wantErr: false,
+		},
+		test{
+			name: "Ignore nested field with unknown path key",
+			args: args{
+				dest: &destination{},
+				partial: map[string]interface{}{
+					"field11.fieldc": str,
+				},
+				tagName:        "json",
+				updaters:       Updaters,
+				skipConditions: SkipConditions,
+			},
+			want:    []string{},
+			wantErr: false,
+		},
+		test{
+			name: "Update slice element out of range with path key",
+			args: args{
+				dest: &destination{},
+				partial: map[string]interface{}{
+					"/field15/1": i,
+				},
+				tagName:        "json",
+				updaters:       Updaters,
+				skipConditions: SkipConditions,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1328,13 +1444,1102 @@ func TestPartialUpdate(t *testing.T) {
 	}
 }
 
+func TestPartialUpdateNested(t *testing.T) {
+	dest := &destination{
+		Field11:  sub{FieldA: "a", FieldB: "b"},
+		Field11p: &sub{FieldA: "a", FieldB: "b"},
+	}
+	partial := map[string]interface{}{
+		"field11": map[string]interface{}{
+			"fielda": "x",
+		},
+		"field11p": map[string]interface{}{
+			"fieldb": "y",
+		},
+	}
+
+	got, err := PartialUpdate(dest, partial, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Field11.FieldA", "Field11p.FieldB"}, got)
+	require.Equal(t, sub{FieldA: "x", FieldB: "b"}, dest.Field11)
+	require.Equal(t, &sub{FieldA: "a", FieldB: "y"}, dest.Field11p)
+}
+
+func TestPartialUpdatePathKeys(t *testing.T) {
+	type item struct {
+		Qty int `json:"qty"`
+	}
+	type model struct {
+		Address *sub            `json:"address"`
+		Items   []item          `json:"items"`
+		Stock   map[string]item `json:"stock"`
+	}
+
+	dest := &model{
+		Items: []item{{Qty: 1}, {Qty: 2}, {Qty: 3}},
+		Stock: map[string]item{"abc": {Qty: 1}},
+	}
+	partial := map[string]interface{}{
+		"address.fielda": "x",
+		"/items/2/qty":   10,
+		"/stock/abc/qty": 6,
+		"stock.def":      map[string]interface{}{"qty": 5},
+	}
+
+	got, err := PartialUpdate(dest, partial, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Items[2].Qty", "Stock[abc].Qty", "Address.FieldA", "Stock[def].Qty"}, got)
+	require.Equal(t, &model{
+		Address: &sub{FieldA: "x"},
+		Items:   []item{{Qty: 1}, {Qty: 2}, {Qty: 10}},
+		Stock:   map[string]item{"abc": {Qty: 6}, "def": {Qty: 5}},
+	}, dest)
+}
+
+func TestAtomicPartialUpdate(t *testing.T) {
+	newDest := func() *destination {
+		return &destination{
+			Field1:   "a",
+			Field11p: &sub{FieldA: "a"},
+			Field14:  []string{"a"},
+		}
+	}
+
+	dest := newDest()
+	partial := map[string]interface{}{
+		"field1":   "b",
+		"field11p": map[string]interface{}{"fielda": "b"},
+		"field14":  []interface{}{"b"},
+		"field13":  1000,
+	}
+	_, err := AtomicPartialUpdate(dest, partial, "json", SkipConditions, Updaters)
+	require.Error(t, err)
+	require.Equal(t, newDest(), dest)
+
+	delete(partial, "field13")
+	got, err := AtomicPartialUpdate(dest, partial, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Field1", "Field11p.FieldA", "Field14"}, got)
+	require.Equal(t, &destination{
+		Field1:   "b",
+		Field11p: &sub{FieldA: "b"},
+		Field14:  []string{"b"},
+	}, dest)
+}
+
+func TestAtomicPartialUpdateKeepsPointers(t *testing.T) {
+	dest := &destination{
+		Field11p: &sub{FieldA: "a"},
+		Field14:  []string{"a"},
+	}
+	field11p, field14 := dest.Field11p, &dest.Field14[0]
+
+	partial := map[string]interface{}{
+		"field11p":   map[string]interface{}{"fielda": "b"},
+		"/field14/0": "b",
+	}
+	_, err := AtomicPartialUpdate(dest, partial, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	// the pointers held into dest see the update
+	require.Same(t, field11p, dest.Field11p)
+	require.Equal(t, "b", field11p.FieldA)
+	require.Same(t, field14, &dest.Field14[0])
+	require.Equal(t, "b", *field14)
+}
+
+func TestAtomicPartialUpdateCyclicDest(t *testing.T) {
+	root := &treeNode{Name: "root"}
+	root.Kids = []*treeNode{{Name: "kid", Parent: root}}
+	kid := root.Kids[0]
+
+	_, err := AtomicPartialUpdate(root, map[string]interface{}{"/kids/0/name": "child", "name": 1}, "json", SkipConditions, Updaters)
+	require.Error(t, err)
+	require.Equal(t, "root", root.Name)
+	require.Equal(t, "kid", kid.Name)
+
+	got, err := AtomicPartialUpdate(root, map[string]interface{}{"/kids/0/name": "child", "/kids/0/parent/name": "tree"}, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Kids[0].Name", "Kids[0].Parent.Name"}, got)
+	require.Same(t, kid, root.Kids[0])
+	require.Same(t, root, kid.Parent)
+	require.Equal(t, "child", kid.Name)
+	require.Equal(t, "tree", root.Name)
+}
+
+func TestExhaustivePartialUpdate(t *testing.T) {
+	dest := &destination{}
+	partial := map[string]interface{}{
+		"field1":     1,
+		"field5":     2,
+		"field11":    map[string]interface{}{"fielda": 3, "fieldb": "b"},
+		"field13":    1000,
+		"/field15/3": "c",
+	}
+
+	got, err := ExhaustivePartialUpdate(dest, partial, "json", SkipConditions, Updaters)
+	require.Error(t, err)
+	require.Equal(t, []string{"Field5", "Field11.FieldB"}, got)
+	require.Equal(t, 2, dest.Field5)
+	require.Equal(t, "b", dest.Field11.FieldB)
+
+	var fieldErrors FieldErrors
+	require.True(t, errors.As(err, &fieldErrors))
+	require.Equal(t, FieldErrors{
+		{Path: "field1", Struct: "destination", Field: "Field1", Key: "field1", Type: reflect.TypeOf(""), Kind: reflect.Int, Value: 1, Reason: ReasonTypeMismatch},
+		{Path: "field11.fielda", Struct: "sub", Field: "FieldA", Key: "fielda", Type: reflect.TypeOf(""), Kind: reflect.Int, Value: 3, Reason: ReasonTypeMismatch},
+		{Path: "field13", Struct: "destination", Field: "Field13", Key: "field13", Type: reflect.TypeOf(int8(0)), Kind: reflect.Int, Value: 1000, Reason: ReasonOverflow},
+		{Path: "/field15/3", Key: "/field15/3", Kind: reflect.String, Value: "c", Reason: ReasonUnknownPath, Err: ErrPathNotFound},
+	}, fieldErrors)
+
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "destination.Field1 cannot be assigned with value 1", fieldError.Error())
+
+	// errors.As and errors.Is before Go 1.20 go through the methods of FieldErrors
+	fieldError = nil
+	require.True(t, fieldErrors.As(&fieldError))
+	require.Equal(t, "field1", fieldError.Path)
+	require.True(t, fieldErrors.Is(ErrPathNotFound))
+	require.False(t, fieldErrors.Is(ErrReadOnly))
+}
+
+func TestPartialUpdateFieldError(t *testing.T) {
+	tests := []struct {
+		name    string
+		partial map[string]interface{}
+		want    Reason
+	}{
+		{"type mismatch", map[string]interface{}{"field1": 1}, ReasonTypeMismatch},
+		{"int8 overflow", map[string]interface{}{"field13": 1000}, ReasonOverflow},
+		{"negative uint", map[string]interface{}{"field12": -1}, ReasonOverflow},
+		{"null not allowed", map[string]interface{}{"field7": nil}, ReasonNullNotAllowed},
+		{"unparseable time", map[string]interface{}{"field9": "yesterday"}, ReasonUnparseableTime},
+		{"slice element", map[string]interface{}{"field15": []interface{}{1, "x"}}, ReasonTypeMismatch},
+		{"index out of range", map[string]interface{}{"/field15/1": 1}, ReasonUnknownPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PartialUpdate(&destination{}, tt.partial, "json", SkipConditions, Updaters)
+			var fieldError *FieldError
+			require.True(t, errors.As(err, &fieldError))
+			require.Equal(t, tt.want, fieldError.Reason)
+		})
+	}
+
+	_, err := PartialUpdate(destination{}, map[string]interface{}{}, "json", SkipConditions, Updaters)
+	require.True(t, errors.Is(err, ErrDestinationMustBePointerType))
+	_, err = PartialUpdate(new(string), map[string]interface{}{}, "json", SkipConditions, Updaters)
+	require.True(t, errors.Is(err, ErrDestinationMustBeStructType))
+}
+
+func TestCheckedUpdaters(t *testing.T) {
+	partial := map[string]interface{}{
+		"field2":   "foo",
+		"field3":   1,
+		"field3p":  1.5,
+		"field4":   uint(2),
+		"field5":   1.0,
+		"field5p":  nil,
+		"field6":   3.0,
+		"field7":   true,
+		"field7p":  nil,
+		"field8":   false,
+		"field9":   "2017-11-22T20:30:26.716Z",
+		"field9p":  1511382626.0,
+		"field10":  "2017-11-22T20:30:26.716Z",
+		"field12":  4.0,
+		"field12p": 4,
+		"field13":  int64(5),
+	}
+	want := destination{}
+	wantUpdated, err := PartialUpdate(&want, partial, "json", SkipConditions, AllUpdaters)
+	require.NoError(t, err)
+	got := destination{}
+	result, err := Apply(&got, partial, WithUpdaters(), WithCheckedUpdaters(AllCheckedUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, wantUpdated, result.Updated)
+	require.Equal(t, want, got)
+
+	// the checked updaters give the reason instead of guessing it
+	for _, tt := range []struct {
+		partial map[string]interface{}
+		want    Reason
+	}{
+		{map[string]interface{}{"field13": 1000}, ReasonOverflow},
+		{map[string]interface{}{"field12p": -1}, ReasonOverflow},
+		{map[string]interface{}{"field9": "yesterday"}, ReasonUnparseableTime},
+		{map[string]interface{}{"field10": "yesterday"}, ReasonUnparseableTime},
+		{map[string]interface{}{"field5": "1"}, ReasonTypeMismatch},
+		{map[string]interface{}{"field6": uint64(math.MaxUint64)}, ReasonOverflow},
+		{map[string]interface{}{"field6": 1e300}, ReasonOverflow},
+		{map[string]interface{}{"field7": "true"}, ReasonTypeMismatch},
+	} {
+		_, err := Apply(&destination{}, tt.partial, WithUpdaters(), WithCheckedUpdaters(AllCheckedUpdaters...))
+		var fieldError *FieldError
+		require.True(t, errors.As(err, &fieldError))
+		require.Equal(t, tt.want, fieldError.Reason)
+		require.NoError(t, fieldError.Err)
+	}
+
+	// a custom updater can wrap a reason into its own error
+	round := UpdaterFunc(func(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+		if fieldValue.Kind() != reflect.Int || v.Kind() != reflect.Float64 {
+			return false, nil
+		}
+		if v.Float() != float64(int64(v.Float())) {
+			return true, fmt.Errorf("not a round number: %w", ReasonTypeMismatch)
+		}
+		fieldValue.SetInt(int64(v.Float()))
+		return true, nil
+	})
+	_, err = Apply(&destination{}, map[string]interface{}{"field5": 1.5}, WithCheckedUpdaters(round))
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.True(t, errors.Is(err, ReasonTypeMismatch))
+	require.Equal(t, ReasonTypeMismatch, fieldError.Reason)
+	require.Equal(t, "destination.Field5 cannot be assigned with value 1.5: not a round number: type mismatch", err.Error())
+
+	// the bool updaters still apply after the checked ones
+	got = destination{}
+	_, err = Apply(&got, map[string]interface{}{"field5": 2.0, "field3": 1}, WithCheckedUpdaters(round))
+	require.NoError(t, err)
+	require.Equal(t, 2, got.Field5)
+	require.Equal(t, 1.0, got.Field3)
+}
+
+func TestSQLNullUpdater(t *testing.T) {
+	type nullable struct {
+		String  sql.NullString  `json:"string"`
+		Int64   sql.NullInt64   `json:"int64"`
+		Int32   sql.NullInt32   `json:"int32"`
+		Int16   sql.NullInt16   `json:"int16"`
+		Byte    sql.NullByte    `json:"byte"`
+		Float64 sql.NullFloat64 `json:"float64"`
+		Bool    sql.NullBool    `json:"bool"`
+		Time    sql.NullTime    `json:"time"`
+	}
+	partial := map[string]interface{}{
+		"string":  "foo",
+		"int64":   1.0,
+		"int32":   2,
+		"int16":   int64(3),
+		"byte":    4.0,
+		"float64": 5,
+		"bool":    true,
+		"time":    "2017-11-22T20:30:26.716Z",
+	}
+	want := nullable{
+		String:  sql.NullString{String: "foo", Valid: true},
+		Int64:   sql.NullInt64{Int64: 1, Valid: true},
+		Int32:   sql.NullInt32{Int32: 2, Valid: true},
+		Int16:   sql.NullInt16{Int16: 3, Valid: true},
+		Byte:    sql.NullByte{Byte: 4, Valid: true},
+		Float64: sql.NullFloat64{Float64: 5, Valid: true},
+		Bool:    sql.NullBool{Bool: true, Valid: true},
+		Time:    sql.NullTime{Time: time.Date(2017, 11, 22, 20, 30, 26, 716000000, time.UTC), Valid: true},
+	}
+
+	for name, opts := range map[string][]Option{
+		"updaters":         {WithUpdaters(AllUpdaters...)},
+		"checked updaters": {WithUpdaters(), WithCheckedUpdaters(AllCheckedUpdaters...)},
+		"registry":         {WithUpdaters(), WithRegistry(StandardRegistry())},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := nullable{}
+			_, err := Apply(&got, partial, opts...)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+
+			nulls := make(map[string]interface{})
+			for key := range partial {
+				nulls[key] = nil
+			}
+			_, err = Apply(&got, nulls, opts...)
+			require.NoError(t, err)
+			require.Equal(t, nullable{}, got)
+
+			for key, tt := range map[string]struct {
+				val  interface{}
+				want Reason
+			}{
+				"byte":  {256, ReasonOverflow},
+				"int16": {40000.0, ReasonOverflow},
+				"time":  {"yesterday", ReasonUnparseableTime},
+				"bool":  {"true", ReasonTypeMismatch},
+			} {
+				_, err := Apply(&got, map[string]interface{}{key: tt.val}, opts...)
+				var fieldError *FieldError
+				require.True(t, errors.As(err, &fieldError), key)
+				require.Equal(t, tt.want, fieldError.Reason, key)
+			}
+			require.Equal(t, nullable{}, got)
+		})
+	}
+}
+
+// int32Wrapper and byteWrapper are nullable types wrapping the smaller database/sql Null types
+type int32Wrapper struct{ sql.NullInt32 }
+
+type byteWrapper struct{ sql.NullByte }
+
+func TestNullTypeUpdater(t *testing.T) {
+	type nullable struct {
+		ZeroString zero.String  `json:"zero_string"`
+		ZeroInt    zero.Int     `json:"zero_int"`
+		ZeroFloat  zero.Float   `json:"zero_float"`
+		ZeroBool   zero.Bool    `json:"zero_bool"`
+		ZeroTime   zero.Time    `json:"zero_time"`
+		NullInt    null.Int     `json:"null_int"`
+		Int32      int32Wrapper `json:"int32"`
+		Byte       byteWrapper  `json:"byte"`
+	}
+	partial := map[string]interface{}{
+		"zero_string": "foo",
+		"zero_int":    "12",
+		"zero_float":  1.5,
+		"zero_bool":   true,
+		"zero_time":   "2017-11-22T20:30:26.716Z",
+		"null_int":    "-3",
+		"int32":       4.0,
+		"byte":        "5",
+	}
+	want := nullable{
+		ZeroString: zero.StringFrom("foo"),
+		ZeroInt:    zero.IntFrom(12),
+		ZeroFloat:  zero.FloatFrom(1.5),
+		ZeroBool:   zero.BoolFrom(true),
+		ZeroTime:   zero.TimeFrom(time.Date(2017, 11, 22, 20, 30, 26, 716000000, time.UTC)),
+		NullInt:    null.IntFrom(-3),
+		Int32:      int32Wrapper{sql.NullInt32{Int32: 4, Valid: true}},
+		Byte:       byteWrapper{sql.NullByte{Byte: 5, Valid: true}},
+	}
+
+	for name, opts := range map[string][]Option{
+		"updaters":         {WithUpdaters(AllUpdaters...)},
+		"checked updaters": {WithUpdaters(), WithCheckedUpdaters(AllCheckedUpdaters...)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := nullable{}
+			_, err := Apply(&got, partial, opts...)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+
+			// null, the empty string for numbers, and zero for the zero package are not valid
+			_, err = Apply(&got, map[string]interface{}{
+				"zero_string": nil,
+				"zero_int":    0,
+				"zero_float":  "",
+				"zero_bool":   false,
+				"zero_time":   nil,
+				"null_int":    nil,
+				"int32":       "",
+				"byte":        nil,
+			}, opts...)
+			require.NoError(t, err)
+			require.Equal(t, nullable{}, got)
+
+			for key, tt := range map[string]struct {
+				val  interface{}
+				want Reason
+			}{
+				"byte":      {256, ReasonOverflow},
+				"int32":     {"x", ReasonTypeMismatch},
+				"zero_time": {"yesterday", ReasonUnparseableTime},
+			} {
+				_, err := Apply(&got, map[string]interface{}{key: tt.val}, opts...)
+				var fieldError *FieldError
+				require.True(t, errors.As(err, &fieldError), key)
+				require.Equal(t, tt.want, fieldError.Reason, key)
+			}
+			require.Equal(t, nullable{}, got)
+		})
+	}
+}
+
+func TestPointerFields(t *testing.T) {
+	type pointers struct {
+		String *string      `json:"string"`
+		Uint16 *uint16      `json:"uint16"`
+		Double **int        `json:"double"`
+		Tags   *[]string    `json:"tags"`
+		Name   *null.String `json:"name"`
+		Sub    *sub         `json:"sub"`
+	}
+
+	got := pointers{}
+	_, err := Apply(&got, map[string]interface{}{
+		"string": "foo",
+		"uint16": 7.0,
+		"double": 3,
+		"tags":   []interface{}{"a", "b"},
+		"name":   "bar",
+		"sub":    map[string]interface{}{"fielda": "a"},
+	}, WithUpdaters(AllUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, "foo", *got.String)
+	require.Equal(t, uint16(7), *got.Uint16)
+	require.Equal(t, 3, **got.Double)
+	require.Equal(t, []string{"a", "b"}, *got.Tags)
+	require.Equal(t, null.StringFrom("bar"), *got.Name)
+	require.Equal(t, sub{FieldA: "a"}, *got.Sub)
+
+	// the pointer is unchanged when the element rejects the value
+	_, err = Apply(&got, map[string]interface{}{"uint16": -1}, WithUpdaters(AllUpdaters...))
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, ReasonOverflow, fieldError.Reason)
+	require.Equal(t, uint16(7), *got.Uint16)
+	_, err = Apply(&got, map[string]interface{}{"double": "x"}, WithUpdaters(AllUpdaters...))
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, ReasonTypeMismatch, fieldError.Reason)
+	require.Equal(t, 3, **got.Double)
+
+	_, err = Apply(&got, map[string]interface{}{
+		"string": nil,
+		"uint16": nil,
+		"double": nil,
+		"tags":   nil,
+		"name":   nil,
+		"sub":    nil,
+	}, WithUpdaters(AllUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, pointers{}, got)
+}
+
+func TestNamedTypes(t *testing.T) {
+	type status string
+	type level int8
+	type ratio float64
+	type flag bool
+	type labels map[string]string
+	type named struct {
+		Status   status     `json:"status"`
+		Level    level      `json:"level"`
+		LevelP   *level     `json:"levelp"`
+		Ratio    ratio      `json:"ratio"`
+		Flag     flag       `json:"flag"`
+		Statuses []status   `json:"statuses"`
+		Labels   labels     `json:"labels"`
+		Alias    sliceAlias `json:"alias"`
+	}
+
+	got := named{}
+	_, err := Apply(&got, map[string]interface{}{
+		"status":   "active",
+		"level":    3,
+		"levelp":   4.0,
+		"ratio":    0.5,
+		"flag":     true,
+		"statuses": []interface{}{"a", "b"},
+		"labels":   map[string]string{"k": "v"},
+		"alias":    []interface{}{"c"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, named{
+		Status:   "active",
+		Level:    3,
+		LevelP:   got.LevelP,
+		Ratio:    0.5,
+		Flag:     true,
+		Statuses: []status{"a", "b"},
+		Labels:   labels{"k": "v"},
+		Alias:    sliceAlias{"c"},
+	}, got)
+	require.Equal(t, level(4), *got.LevelP)
+
+	// values of the same kind that do not convert to the field type are errors, not panics
+	for key, val := range map[string]interface{}{
+		"level":  1000,
+		"labels": map[string]interface{}{"k": 1},
+		"status": struct{}{},
+	} {
+		_, err := Apply(&got, map[string]interface{}{key: val})
+		var fieldError *FieldError
+		require.True(t, errors.As(err, &fieldError), key)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	panicking := func(fieldValue reflect.Value, v reflect.Value) bool {
+		panic("boom")
+	}
+	_, err := Apply(&destination{}, map[string]interface{}{"field11": map[string]interface{}{"fielda": 1}}, WithUpdaters(panicking))
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.True(t, errors.Is(err, ErrPanic))
+	require.Equal(t, "field11.fielda", fieldError.Path)
+	require.Equal(t, "sub.FieldA cannot be assigned with value 1: Recovered from a panic: boom", err.Error())
+
+	_, err = JSONPatch(&destination{}, []Operation{{Op: OpReplace, Path: "/field5", Value: "x"}}, "json", SkipConditions, []func(reflect.Value, reflect.Value) bool{panicking})
+	require.True(t, errors.As(err, &fieldError))
+	require.True(t, errors.Is(err, ErrPanic))
+	require.Equal(t, "/field5", fieldError.Path)
+
+	// values that used to panic inside the built-in updaters
+	for _, partial := range []map[string]interface{}{
+		{"field14": "a"},
+		{"field15": 1},
+	} {
+		_, err := PartialUpdate(&destination{}, partial, "json", SkipConditions, AllUpdaters)
+		require.True(t, errors.As(err, &fieldError))
+		require.False(t, errors.Is(err, ErrPanic))
+	}
+}
+
+// FuzzPartialUpdate checks that the built-in updaters reject any JSON without panicking
+func FuzzPartialUpdate(f *testing.F) {
+	for _, seed := range []string{
+		`{"field1": "foo", "field5p": 1, "field9": "2017-11-22T20:30:26.716Z", "field11": {"fielda": "a"}}`,
+		`{"field13": 1000, "field12": -1, "field14": null, "field15": [1, "x", null]}`,
+		`{"field16": [[1]], "field11p": null, "/field15/0": 2, "field11.fielda": true}`,
+		`{"field2": null, "field4": "1", "field6": 1.5, "field8": 0, "field10": 1}`,
+		`{"field13": {"$inc": 1000}, "field15": {"$push": [1], "$pull": "x"}, "field9": {"$currentDate": true}, "field1": {"$max": "b"}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var partial map[string]interface{}
+		if err := json.Unmarshal(data, &partial); err != nil {
+			return
+		}
+		for _, opts := range [][]Option{
+			{WithUpdaters(AllUpdaters...)},
+			{WithUpdaters(AllUpdaters...), WithCollectErrors()},
+			{WithRegistry(StandardRegistry()), WithMergePatch()},
+			{WithUpdaters(), WithCheckedUpdaters(AllCheckedUpdaters...), WithAtomic()},
+			{WithUpdaters(AllUpdaters...), WithOperators(), WithCollectErrors()},
+		} {
+			if _, err := Apply(&destination{}, partial, opts...); errors.Is(err, ErrPanic) {
+				t.Fatalf("%v: %v", string(data), err)
+			}
+		}
+	})
+}
+
+func TestNullPolicy(t *testing.T) {
+	type nullable struct {
+		Tags    []string          `json:"tags"`
+		Labels  map[string]string `json:"labels"`
+		Name    string            `json:"name"`
+		Count   int               `json:"count" patch:"null=zero"`
+		Sub     sub               `json:"sub"`
+		Strict  string            `json:"strict" patch:"null=reject"`
+		Nested  *nullable         `json:"nested"`
+		Updated time.Time         `json:"updated"`
+	}
+	full := func() nullable {
+		return nullable{
+			Tags:    []string{"a"},
+			Labels:  map[string]string{"k": "v"},
+			Name:    "foo",
+			Count:   1,
+			Sub:     sub{FieldA: "a"},
+			Strict:  "bar",
+			Nested:  &nullable{Count: 2},
+			Updated: time.Now(),
+		}
+	}
+
+	// slices, maps and pointers are always set to nil, the tag overrides the default policy
+	got := full()
+	_, err := Apply(&got, map[string]interface{}{"tags": nil, "labels": nil, "count": nil, "/nested/count": nil})
+	require.NoError(t, err)
+	require.Nil(t, got.Tags)
+	require.Nil(t, got.Labels)
+	require.Equal(t, 0, got.Count)
+	require.Equal(t, 0, got.Nested.Count)
+
+	for _, key := range []string{"name", "sub", "strict", "updated"} {
+		_, err := Apply(&got, map[string]interface{}{key: nil})
+		var fieldError *FieldError
+		require.True(t, errors.As(err, &fieldError), key)
+		require.Equal(t, ReasonNullNotAllowed, fieldError.Reason, key)
+	}
+
+	// the policy of a Patcher resets the other fields, except the ones whose tag rejects null
+	patcher := NewPatcher(WithNullPolicy(NullAsZero))
+	got = full()
+	result, err := patcher.Apply(&got, map[string]interface{}{"name": nil, "sub": nil, "updated": nil, "nested": map[string]interface{}{"name": nil}})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Name", "Sub", "Updated", "Nested.Name"}, result.Updated)
+	require.Equal(t, "", got.Name)
+	require.Equal(t, sub{}, got.Sub)
+	require.True(t, got.Updated.IsZero())
+
+	_, err = patcher.Apply(&got, map[string]interface{}{"strict": nil})
+	require.Error(t, err)
+	_, err = patcher.Apply(&got, map[string]interface{}{"/strict": nil})
+	require.Error(t, err)
+	_, err = patcher.JSONPatch(&got, []Operation{{Op: OpReplace, Path: "/strict", Value: nil}})
+	require.Error(t, err)
+	require.Equal(t, "bar", got.Strict)
+	_, err = JSONPatch(&got, []Operation{{Op: OpReplace, Path: "/count", Value: nil}}, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, 0, got.Count)
+}
+
+func TestSliceElementErrors(t *testing.T) {
+	dest := destination{Field15: []int{7}}
+	_, err := PartialUpdate(&dest, map[string]interface{}{"field15": []interface{}{1, "x"}}, "json", SkipConditions, Updaters)
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "field15[1]", fieldError.Path)
+	require.Equal(t, "Field15[1]", fieldError.Field)
+	require.Equal(t, "1", fieldError.Key)
+	require.Equal(t, reflect.TypeOf(0), fieldError.Type)
+	require.Equal(t, ReasonTypeMismatch, fieldError.Reason)
+	require.EqualError(t, err, "field15[1] cannot be assigned with value x")
+	require.Equal(t, []int{7}, dest.Field15)
+
+	// every rejected element is collected, null follows the null policy
+	_, err = ExhaustivePartialUpdate(&dest, map[string]interface{}{"field15": []interface{}{"x", 2, nil}}, "json", SkipConditions, Updaters)
+	var fieldErrors FieldErrors
+	require.True(t, errors.As(err, &fieldErrors))
+	require.Len(t, fieldErrors, 2)
+	require.Equal(t, "field15[0]", fieldErrors[0].Path)
+	require.Equal(t, "field15[2]", fieldErrors[1].Path)
+	require.Equal(t, ReasonNullNotAllowed, fieldErrors[1].Reason)
+	require.Equal(t, []int{7}, dest.Field15)
+
+	result, err := NewPatcher(WithNullPolicy(NullAsZero)).Apply(&dest, map[string]interface{}{"field15": []interface{}{1, nil}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Field15"}, result.Updated)
+	require.Equal(t, []int{1, 0}, dest.Field15)
+}
+
+type level int
+
+// levelUpdater converts a string to the level of its length
+func levelUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	if fieldValue.Type() != reflect.TypeOf(level(0)) || v.Kind() != reflect.String {
+		return false
+	}
+	fieldValue.SetInt(int64(len(v.String())))
+	return true
+}
+
+func TestElementUpdaters(t *testing.T) {
+	type item struct {
+		ID   int    `custom:"id" props:"readonly"`
+		Name string `custom:"name"`
+	}
+	type leveled struct {
+		Levels []level           `custom:"levels"`
+		ByName map[string]level  `custom:"by_name"`
+		Items  []item            `custom:"items"`
+		Names  map[string]string `custom:"names"`
+	}
+	updaters := append([]func(reflect.Value, reflect.Value) bool{levelUpdater}, Updaters...)
+
+	// elements are converted with the updaters, skip conditions and tag name of the update
+	dest := leveled{ByName: map[string]level{"old": 1}}
+	got, err := PartialUpdate(&dest, map[string]interface{}{
+		"levels":  []interface{}{"a", "bbb", 2},
+		"by_name": map[string]interface{}{"x": "cc"},
+		"items":   []interface{}{map[string]interface{}{"id": 5, "name": "n"}},
+	}, "custom", SkipConditions, updaters)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Levels", "ByName", "Items"}, got)
+	require.Equal(t, []level{1, 3, 2}, dest.Levels)
+	require.Equal(t, map[string]level{"x": 2}, dest.ByName)
+	require.Equal(t, []item{{Name: "n"}}, dest.Items)
+
+	_, err = PartialUpdate(&dest, map[string]interface{}{"levels": []interface{}{"a"}}, "custom", SkipConditions, Updaters)
+	require.Error(t, err)
+
+	// rejected entries and fields of struct elements are reported at their path
+	_, err = PartialUpdate(&dest, map[string]interface{}{"by_name": map[string]interface{}{"x": true}}, "custom", SkipConditions, updaters)
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "by_name.x", fieldError.Path)
+	require.Equal(t, "ByName[x]", fieldError.Field)
+	require.Equal(t, map[string]level{"x": 2}, dest.ByName)
+
+	_, err = PartialUpdate(&dest, map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": 1}}}, "custom", SkipConditions, updaters)
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "items[0].name", fieldError.Path)
+	require.Equal(t, "item", fieldError.Struct)
+	require.Equal(t, []item{{Name: "n"}}, dest.Items)
+
+	// objects decoded from JSON fill maps of any element type
+	_, err = PartialUpdate(&dest, map[string]interface{}{"names": map[string]interface{}{"a": "b"}}, "custom", SkipConditions, updaters)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "b"}, dest.Names)
+}
+
+type part struct {
+	ID int `json:"id"`
+}
+
+type mergedItem struct {
+	ID    int    `json:"id"`
+	SKU   string `json:"sku"`
+	Qty   int    `json:"qty"`
+	Parts []part `json:"parts"`
+}
+
+type mergedOrder struct {
+	Items    []mergedItem  `json:"items" patch:"mergeKey=id"`
+	Pointers []*mergedItem `json:"pointers" patch:"mergeKey=sku"`
+	Broken   []mergedItem  `json:"broken" patch:"mergeKey=nope"`
+}
+
+func TestMergeKey(t *testing.T) {
+	newMergedOrder := func() mergedOrder {
+		return mergedOrder{
+			Items:    []mergedItem{{ID: 1, SKU: "a", Qty: 1, Parts: []part{{8}}}, {ID: 2, SKU: "b", Qty: 2}, {ID: 3, SKU: "c", Qty: 3}},
+			Pointers: []*mergedItem{{ID: 1, SKU: "a", Qty: 1}},
+		}
+	}
+
+	// matched elements are updated, the others appended or removed, the elements do not merge their own slices
+	got := newMergedOrder()
+	first := got.Pointers[0]
+	updated, err := PartialUpdate(&got, map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"id": 2, "qty": 5},
+			map[string]interface{}{"id": 4, "sku": "d"},
+			map[string]interface{}{"id": 3, DirectiveKey: DirectiveDelete},
+			map[string]interface{}{"id": 1, "parts": []interface{}{map[string]interface{}{"id": 9}}},
+		},
+		"pointers": []interface{}{map[string]interface{}{"sku": "a", "qty": 7}, map[string]interface{}{"qty": 1}},
+	}, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Items", "Pointers"}, updated)
+	require.Equal(t, []mergedItem{{ID: 1, SKU: "a", Qty: 1, Parts: []part{{9}}}, {ID: 2, SKU: "b", Qty: 5}, {ID: 4, SKU: "d"}}, got.Items)
+	require.Len(t, got.Pointers, 2)
+	require.Equal(t, mergedItem{ID: 1, SKU: "a", Qty: 7}, *got.Pointers[0])
+	require.Equal(t, mergedItem{Qty: 1}, *got.Pointers[1])
+	require.Equal(t, 1, first.Qty)
+
+	// rejected objects are reported at their index and leave the slice unchanged
+	tests := []struct {
+		name   string
+		items  []interface{}
+		path   string
+		reason Reason
+	}{
+		{"field of an element", []interface{}{map[string]interface{}{"id": 4}, map[string]interface{}{"id": 2, "qty": "x"}}, "items[1].qty", ReasonTypeMismatch},
+		{"merge key", []interface{}{map[string]interface{}{"id": "x"}}, "items[0].id", ReasonTypeMismatch},
+		{"not an object", []interface{}{1}, "items[0]", ReasonTypeMismatch},
+		{"unknown directive", []interface{}{map[string]interface{}{"id": 1, DirectiveKey: "drop"}}, "items[0].$patch", ReasonTypeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newMergedOrder()
+			_, err := PartialUpdate(&got, map[string]interface{}{"items": tt.items}, "json", SkipConditions, Updaters)
+			var fieldError *FieldError
+			require.True(t, errors.As(err, &fieldError))
+			require.Equal(t, tt.path, fieldError.Path)
+			require.Equal(t, tt.reason, fieldError.Reason)
+			require.Equal(t, newMergedOrder().Items, got.Items)
+		})
+	}
+
+	got = newMergedOrder()
+	_, err = PartialUpdate(&got, map[string]interface{}{"broken": []interface{}{map[string]interface{}{"id": 1}}}, "json", SkipConditions, Updaters)
+	require.True(t, errors.Is(err, ErrMergeKeyNotFound))
+
+	// null and values other than arrays are assigned as usual
+	_, err = PartialUpdate(&got, map[string]interface{}{"items": nil}, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Nil(t, got.Items)
+
+	// appended elements get the merge key even when it is read only, so that later merges match them
+	type lockedItem struct {
+		ID  int `json:"id" props:"readonly"`
+		Qty int `json:"qty"`
+	}
+	type lockedOrder struct {
+		Items []lockedItem `json:"items" patch:"mergeKey=id"`
+	}
+	locked := lockedOrder{Items: []lockedItem{{ID: 1, Qty: 1}}}
+	_, err = PartialUpdate(&locked, map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 3, "qty": 1}}}, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	_, err = PartialUpdate(&locked, map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 3, "qty": 2}}}, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, []lockedItem{{ID: 1, Qty: 1}, {ID: 3, Qty: 2}}, locked.Items)
+
+	// arrays replace the slice in a merge patch
+	got = newMergedOrder()
+	_, err = MergePatch(&got, map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 2, "qty": 5}}}, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, []mergedItem{{ID: 2, Qty: 5}}, got.Items)
+}
+
+func TestSliceOperators(t *testing.T) {
+	type tagged struct {
+		Tags  []string     `json:"tags"`
+		Codes []level      `json:"codes"`
+		Items []mergedItem `json:"items" patch:"mergeKey=id"`
+		Name  string       `json:"name"`
+	}
+	updaters := append([]func(reflect.Value, reflect.Value) bool{levelUpdater}, Updaters...)
+	tests := []struct {
+		name    string
+		dest    tagged
+		partial map[string]interface{}
+		want    tagged
+	}{
+		{
+			name:    "push",
+			dest:    tagged{Tags: []string{"a"}},
+			partial: map[string]interface{}{"tags": map[string]interface{}{OperatorPush: []interface{}{"b", "a"}}},
+			want:    tagged{Tags: []string{"a", "b", "a"}},
+		},
+		{
+			name:    "push to a nil slice",
+			partial: map[string]interface{}{"tags": map[string]interface{}{OperatorPush: "a"}},
+			want:    tagged{Tags: []string{"a"}},
+		},
+		{
+			name:    "prepend",
+			dest:    tagged{Tags: []string{"a"}},
+			partial: map[string]interface{}{"tags": map[string]interface{}{OperatorPrepend: []interface{}{"b", "c"}}},
+			want:    tagged{Tags: []string{"b", "c", "a"}},
+		},
+		{
+			name:    "pull",
+			dest:    tagged{Tags: []string{"a", "b", "a", "c"}},
+			partial: map[string]interface{}{"tags": map[string]interface{}{OperatorPull: []interface{}{"a", "x"}}},
+			want:    tagged{Tags: []string{"b", "c"}},
+		},
+		{
+			name:    "add to set",
+			dest:    tagged{Tags: []string{"a"}},
+			partial: map[string]interface{}{"tags": map[string]interface{}{OperatorAddToSet: []interface{}{"a", "b", "b"}}},
+			want:    tagged{Tags: []string{"a", "b"}},
+		},
+		{
+			name:    "pull before push",
+			dest:    tagged{Tags: []string{"a", "b"}},
+			partial: map[string]interface{}{"tags": map[string]interface{}{OperatorPush: "a", OperatorPull: "a"}},
+			want:    tagged{Tags: []string{"b", "a"}},
+		},
+		{
+			name:    "elements converted by the updaters",
+			dest:    tagged{Codes: []level{1, 3}},
+			partial: map[string]interface{}{"codes": map[string]interface{}{OperatorPull: "abc", OperatorPush: []interface{}{"ab", 5}}},
+			want:    tagged{Codes: []level{1, 2, 5}},
+		},
+		{
+			name:    "structs",
+			dest:    tagged{Items: []mergedItem{{ID: 1}, {ID: 2}}},
+			partial: map[string]interface{}{"items": map[string]interface{}{OperatorPull: map[string]interface{}{"id": 1}}},
+			want:    tagged{Items: []mergedItem{{ID: 2}}},
+		},
+		{
+			name:    "structs pulled by merge key",
+			dest:    tagged{Items: []mergedItem{{ID: 1, Qty: 5}, {ID: 2, Qty: 1}, {ID: 3}}},
+			partial: map[string]interface{}{"items": map[string]interface{}{OperatorPull: []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 3.0, "qty": 7}}}},
+			want:    tagged{Items: []mergedItem{{ID: 2, Qty: 1}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Apply(&tt.dest, tt.partial, WithUpdaters(updaters...), WithOperators())
+			require.NoError(t, err)
+			require.Len(t, result.Updated, 1)
+			require.Equal(t, tt.want, tt.dest)
+		})
+	}
+
+	// rejected elements and unknown operators leave the slice unchanged
+	dest := tagged{Tags: []string{"a"}}
+	_, err := Apply(&dest, map[string]interface{}{"tags": map[string]interface{}{OperatorPush: []interface{}{"b", 1}}}, WithUpdaters(updaters...), WithOperators())
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "tags.$push[1]", fieldError.Path)
+	require.Equal(t, ReasonTypeMismatch, fieldError.Reason)
+
+	_, err = Apply(&dest, map[string]interface{}{"tags": map[string]interface{}{OperatorPull: "a", "$each": 1, "b": 2}}, WithUpdaters(updaters...), WithOperators(), WithCollectErrors())
+	var fieldErrors FieldErrors
+	require.True(t, errors.As(err, &fieldErrors))
+	require.Len(t, fieldErrors, 2)
+	require.Equal(t, "tags.$each", fieldErrors[0].Path)
+	require.True(t, errors.Is(err, ErrUnknownOperator))
+	require.Equal(t, []string{"a"}, dest.Tags)
+
+	dest = tagged{Items: []mergedItem{{ID: 1}}}
+	_, err = Apply(&dest, map[string]interface{}{"items": map[string]interface{}{OperatorPull: map[string]interface{}{"id": "x"}}}, WithUpdaters(updaters...), WithOperators())
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "items.$pull[0].id", fieldError.Path)
+	require.Equal(t, []mergedItem{{ID: 1}}, dest.Items)
+
+	// objects without operators are not operators
+	_, err = Apply(&dest, map[string]interface{}{"tags": map[string]interface{}{"push": "b"}}, WithUpdaters(updaters...), WithOperators())
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "tags", fieldError.Path)
+
+	// without WithOperators, and in a merge patch, objects of operators are data assigned as usual
+	dest = tagged{Tags: []string{"a"}}
+	_, err = PartialUpdate(&dest, map[string]interface{}{"tags": map[string]interface{}{OperatorPush: "b"}}, "json", SkipConditions, updaters)
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "tags", fieldError.Path)
+	_, err = MergePatch(&dest, map[string]interface{}{"tags": map[string]interface{}{OperatorPush: "b"}}, "json", SkipConditions, updaters)
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, []string{"a"}, dest.Tags)
+}
+
+type counters struct {
+	Views     int8       `json:"views"`
+	Total     uint       `json:"total"`
+	Big       int64      `json:"big"`
+	Score     float32    `json:"score"`
+	Visits    *int       `json:"visits"`
+	Balance   null.Float `json:"balance"`
+	Orders    null.Int   `json:"orders"`
+	Low       int        `json:"low"`
+	Name      string     `json:"name"`
+	SeenAt    time.Time  `json:"seen_at"`
+	TouchedAt *time.Time `json:"touched_at"`
+	Joined    null.Time  `json:"joined"`
+	Tags      []string   `json:"tags"`
+	Sub       sub        `json:"sub"`
+}
+
+func TestFieldOperators(t *testing.T) {
+	seen := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newCounters := func() counters {
+		return counters{
+			Views:   5,
+			Big:     math.MaxInt64,
+			Score:   1.5,
+			Balance: null.FloatFrom(1.5),
+			Low:     5,
+			Name:    "a",
+			SeenAt:  seen,
+			Tags:    []string{"a"},
+			Sub:     sub{FieldA: "a"},
+		}
+	}
+	op := func(operator string, operand interface{}) map[string]interface{} {
+		return map[string]interface{}{operator: operand}
+	}
+
+	got := newCounters()
+	before := time.Now()
+	result, err := Apply(&got, map[string]interface{}{
+		"views":      op(OperatorInc, 1),
+		"total":      op(OperatorInc, 2.0),
+		"score":      op(OperatorMul, 2),
+		"visits":     op(OperatorInc, 2),
+		"balance":    op(OperatorMul, 2),
+		"orders":     op(OperatorInc, 3),
+		"low":        map[string]interface{}{OperatorMin: 3, OperatorMax: 4},
+		"name":       op(OperatorMax, "b"),
+		"seen_at":    op(OperatorMin, "2010-01-01T00:00:00Z"),
+		"touched_at": op(OperatorCurrentDate, true),
+		"joined":     op(OperatorCurrentDate, true),
+		"tags":       op(OperatorPush, "b"),
+		"sub":        op(OperatorUnset, ""),
+	}, WithOperators())
+	require.NoError(t, err)
+	require.Len(t, result.Updated, 13)
+	require.Equal(t, int8(6), got.Views)
+	require.Equal(t, uint(2), got.Total)
+	require.Equal(t, float32(3), got.Score)
+	require.Equal(t, 2, *got.Visits)
+	require.Equal(t, null.FloatFrom(3), got.Balance)
+	require.Equal(t, null.IntFrom(3), got.Orders)
+	require.Equal(t, 4, got.Low)
+	require.Equal(t, "b", got.Name)
+	require.True(t, got.SeenAt.Equal(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.False(t, got.TouchedAt.Before(before.Truncate(time.Second)))
+	require.True(t, got.Joined.Valid)
+	require.Equal(t, []string{"a", "b"}, got.Tags)
+	require.Equal(t, sub{}, got.Sub)
+
+	// values that do not change the field are not reported as updated, and null counts as the lowest value
+	got = newCounters()
+	result, err = Apply(&got, map[string]interface{}{
+		"low":     op(OperatorMin, 7),
+		"name":    op(OperatorMax, "a"),
+		"seen_at": op(OperatorMax, "2010-01-01T00:00:00Z"),
+		"visits":  op(OperatorMax, 1),
+		"tags":    op(OperatorUnset, true),
+	}, WithOperators())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Visits", "Tags"}, result.Updated)
+	require.Equal(t, 5, got.Low)
+	require.Equal(t, "a", got.Name)
+	require.Equal(t, seen, got.SeenAt)
+	require.Equal(t, 1, *got.Visits)
+	require.Nil(t, got.Tags)
+
+	// results that do not fit and operands that do not apply leave the field unchanged
+	tests := []struct {
+		name    string
+		partial map[string]interface{}
+		path    string
+		reason  Reason
+	}{
+		{"int8 overflow", map[string]interface{}{"views": op(OperatorInc, 200)}, "views.$inc", ReasonOverflow},
+		{"negative uint", map[string]interface{}{"total": op(OperatorInc, -1)}, "total.$inc", ReasonOverflow},
+		{"int64 overflow", map[string]interface{}{"big": op(OperatorInc, 1)}, "big.$inc", ReasonOverflow},
+		{"not a number", map[string]interface{}{"name": op(OperatorInc, 1)}, "name.$inc", ReasonTypeMismatch},
+		{"operand not a number", map[string]interface{}{"views": op(OperatorMul, "2")}, "views.$mul", ReasonTypeMismatch},
+		{"fraction for an int", map[string]interface{}{"views": op(OperatorInc, 1.5)}, "views.$inc", ReasonTypeMismatch},
+		{"fraction for a null int", map[string]interface{}{"orders": op(OperatorMul, 0.5)}, "orders.$mul", ReasonTypeMismatch},
+		{"null operand", map[string]interface{}{"low": op(OperatorMin, nil)}, "low.$min", ReasonNullNotAllowed},
+		{"unparseable time", map[string]interface{}{"seen_at": op(OperatorMax, "later")}, "seen_at.$max", ReasonUnparseableTime},
+		{"not comparable", map[string]interface{}{"sub": op(OperatorMax, map[string]interface{}{})}, "sub.$max", ReasonTypeMismatch},
+		{"current date of a string", map[string]interface{}{"name": op(OperatorCurrentDate, true)}, "name.$currentDate", ReasonTypeMismatch},
+		{"current date not true", map[string]interface{}{"seen_at": op(OperatorCurrentDate, "now")}, "seen_at.$currentDate", ReasonTypeMismatch},
+		{"unknown operator", map[string]interface{}{"views": op(OperatorPush, 1)}, "views.$push", ReasonTypeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newCounters()
+			_, err := Apply(&got, tt.partial, WithOperators())
+			var fieldError *FieldError
+			require.True(t, errors.As(err, &fieldError))
+			require.Equal(t, tt.path, fieldError.Path)
+			require.Equal(t, tt.reason, fieldError.Reason)
+			require.Equal(t, newCounters(), got)
+		})
+	}
+
+	// without WithOperators, objects are assigned as usual
+	got = newCounters()
+	_, err = Apply(&got, map[string]interface{}{"views": op(OperatorInc, 1)})
+	require.Error(t, err)
+	_, err = Apply(&got, map[string]interface{}{"sub": op(OperatorUnset, "")})
+	require.NoError(t, err)
+	require.Equal(t, newCounters(), got)
+}
+
+func TestPartialUpdateConcurrentPlans(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// the same type is planned for two tag names concurrently
+			tagName, key := "json", "field5"
+			if i%2 == 0 {
+				tagName, key = "", "Field5"
+			}
+			dest := &destination{}
+			got, err := PartialUpdate(dest, map[string]interface{}{key: i}, tagName, SkipConditions, Updaters)
+			require.NoError(t, err)
+			require.Equal(t, []string{"Field5"}, got)
+			require.Equal(t, i, dest.Field5)
+		}(i)
+	}
+	wg.Wait()
+}
+
 //goos: linux
 //goarch: amd64
 //pkg: github.com/nandaryanizar/gopartial
 //BenchmarkPartialUpdate
-//BenchmarkPartialUpdate-8          210183              5051 ns/op             1000 B/op         51 allocs/op
+//BenchmarkPartialUpdate          880986              2876 ns/op              328 B/op          7 allocs/op
 //PASS
-//ok      github.com/nandaryanizar/gopartial      1.124s
+//ok      github.com/nandaryanizar/gopartial      1.159s
 func BenchmarkPartialUpdate(b *testing.B) {
 	dest := new(destination)
 	input := map[string]interface{}{
@@ -1356,3 +2561,40 @@ func BenchmarkPartialUpdate(b *testing.B) {
 	}
 
 }
+
+// benchmarkInput is the partial of BenchmarkPartialUpdate
+func benchmarkInput() map[string]interface{} {
+	return map[string]interface{}{
+		"field1":  "test",
+		"field5p": 1,
+		"field14": []interface{}{"abc", "def"},
+		"field15": []interface{}{1, 2},
+	}
+}
+
+//BenchmarkPatcher                456519              2817 ns/op              168 B/op          6 allocs/op
+//BenchmarkPatcherRegistry        367123              2763 ns/op              168 B/op          6 allocs/op
+
+// BenchmarkPatcher caches the skip conditions in the plans of the Patcher
+func BenchmarkPatcher(b *testing.B) {
+	dest := new(destination)
+	input := benchmarkInput()
+	patcher := NewPatcher()
+
+	for i := 0; i < b.N; i++ {
+		_, err := patcher.Apply(dest, input)
+		require.NoError(b, err)
+	}
+}
+
+// BenchmarkPatcherRegistry also looks the updaters up by field type instead of trying them in order
+func BenchmarkPatcherRegistry(b *testing.B) {
+	dest := new(destination)
+	input := benchmarkInput()
+	patcher := NewPatcher(WithRegistry(StandardRegistry()), WithUpdaters())
+
+	for i := 0; i < b.N; i++ {
+		_, err := patcher.Apply(dest, input)
+		require.NoError(b, err)
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/nandaryanizar/gopartial/internal/nullv5test
+
+go 1.22
+
+require (
+	github.com/guregu/null/v5 v5.0.0
+	github.com/nandaryanizar/gopartial v0.0.0
+	github.com/stretchr/testify v1.6.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/guregu/null v4.0.0+incompatible // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
+
+replace github.com/nandaryanizar/gopartial => ../..
@@ -0,0 +1,82 @@
+// Package nullv5test tests the updaters against the types of github.com/guregu/null/v5,
+// in a module of its own since v5 needs a newer Go than gopartial.
+package nullv5test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/guregu/null/v5/zero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nandaryanizar/gopartial"
+)
+
+type nullable struct {
+	Count    null.Value[int16]  `json:"count"`
+	Name     null.Value[string] `json:"name"`
+	Byte     null.Byte          `json:"byte"`
+	Int32    null.Int32         `json:"int32"`
+	Int16    null.Int16         `json:"int16"`
+	Int      null.Int           `json:"int"`
+	Float    null.Float         `json:"float"`
+	Time     null.Time          `json:"time"`
+	ZeroInt  zero.Int           `json:"zero_int"`
+	ZeroTime zero.Time          `json:"zero_time"`
+}
+
+func TestNullV5(t *testing.T) {
+	seen := time.Date(2017, 11, 22, 20, 30, 26, 0, time.UTC)
+	got := nullable{}
+	result, err := gopartial.Apply(&got, map[string]interface{}{
+		"count":     "12",
+		"name":      "foo",
+		"byte":      7,
+		"int32":     "-3",
+		"int16":     4.0,
+		"int":       uint(5),
+		"float":     "1.5",
+		"time":      "2017-11-22T20:30:26Z",
+		"zero_int":  0,
+		"zero_time": "2017-11-22T20:30:26Z",
+	}, gopartial.WithUpdaters(gopartial.AllUpdaters...))
+	require.NoError(t, err)
+	require.Len(t, result.Updated, 10)
+	require.Equal(t, nullable{
+		Count:    null.ValueFrom[int16](12),
+		Name:     null.ValueFrom("foo"),
+		Byte:     null.ByteFrom(7),
+		Int32:    null.Int32From(-3),
+		Int16:    null.Int16From(4),
+		Int:      null.IntFrom(5),
+		Float:    null.FloatFrom(1.5),
+		Time:     null.TimeFrom(seen),
+		ZeroInt:  zero.IntFrom(0),
+		ZeroTime: zero.TimeFrom(seen),
+	}, got)
+
+	// null makes the null types invalid and sets the zero types to their zero value
+	_, err = gopartial.Apply(&got, map[string]interface{}{
+		"count":     nil,
+		"name":      nil,
+		"byte":      nil,
+		"int32":     "",
+		"int16":     nil,
+		"int":       nil,
+		"float":     nil,
+		"time":      nil,
+		"zero_int":  nil,
+		"zero_time": nil,
+	}, gopartial.WithUpdaters(gopartial.AllUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, nullable{}, got)
+
+	// numbers that do not fit are rejected
+	_, err = gopartial.Apply(&got, map[string]interface{}{"byte": 256}, gopartial.WithUpdaters(gopartial.AllUpdaters...))
+	var fieldError *gopartial.FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, gopartial.ReasonOverflow, fieldError.Reason)
+	require.Equal(t, nullable{}, got)
+}
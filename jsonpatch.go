@@ -0,0 +1,298 @@
+package gopartial
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSON Patch operation names
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation is a single JSON Patch operation
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// JSONPatch applies a list of JSON Patch operations (RFC 6902) to destination object
+// (Must be a pointer to a struct), in order. Paths are JSON Pointers (RFC 6901) where
+// struct fields are looked up by tagName the same way as PartialUpdate, and values are
+// assigned through the updaters. The patch is atomic: if any operation fails,
+// dest is left unchanged, and the fields patched are updated in place like AtomicPartialUpdate.
+// Returns list of paths (struct field names, slice indexes and map keys) that were modified.
+func JSONPatch(dest interface{}, operations []Operation, tagName string, skipConditions []func(reflect.StructField) bool, updaters []func(reflect.Value, reflect.Value) bool) ([]string, error) {
+	return newConfig(positional(tagName, skipConditions, updaters)...).jsonPatch(dest, operations)
+}
+
+// jsonPatch implements JSONPatch
+func (c *config) jsonPatch(dest interface{}, operations []Operation) (pathsUpdated []string, err error) {
+	// the panics of a value are errors of its path, this only catches the others
+	defer func() {
+		if r := recover(); r != nil {
+			pathsUpdated, err = nil, recovered(r)
+		}
+	}()
+
+	valueOfDest, err := structValue(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	// operations are applied to a copy of the fields they modify, committed to dest only when all of them succeed
+	indexes := c.patchedFields(valueOfDest, operations)
+	cp := newCopier()
+	document := cp.stage(valueOfDest, indexes)
+	pathsUpdated = make([]string, 0)
+	for _, operation := range operations {
+		updated, err := c.applyOperation(document, operation)
+		if err != nil {
+			return nil, err
+		}
+		pathsUpdated = append(pathsUpdated, updated...)
+	}
+
+	cp.commitStaged(valueOfDest, document, indexes)
+	return pathsUpdated, nil
+}
+
+// patchedFields returns the indexes of the fields of valueOfDest that operations may modify,
+// the first token of their path or of the path a value is moved from, or every field when
+// an operation replaces the whole document
+func (c *config) patchedFields(valueOfDest reflect.Value, operations []Operation) []int {
+	indexes := make([]int, 0, len(operations))
+	for _, operation := range operations {
+		if operation.Op == OpTest {
+			continue
+		}
+		pointers := []string{operation.Path}
+		if operation.Op == OpMove {
+			pointers = append(pointers, operation.From)
+		}
+		for _, pointer := range pointers {
+			if pointer == "" {
+				return c.fieldIndexes(valueOfDest.Type())
+			}
+			tokens, err := parsePointer(pointer)
+			if err != nil || len(tokens) == 0 {
+				continue
+			}
+			if field, err := c.fieldByKey(valueOfDest, tokens[0]); err == nil && !containsIndex(indexes, field.index) {
+				indexes = append(indexes, field.index)
+			}
+		}
+	}
+	return indexes
+}
+
+// fieldIndexes returns the indexes of every field of the plan of t
+func (c *config) fieldIndexes(t reflect.Type) []int {
+	fields := c.planFor(t).fields
+	indexes := make([]int, len(fields))
+	for i := range fields {
+		indexes[i] = fields[i].index
+	}
+	return indexes
+}
+
+// applyOperation applies a single operation to document
+func (c *config) applyOperation(document reflect.Value, operation Operation) ([]string, error) {
+	switch operation.Op {
+	case OpAdd, OpReplace:
+		if operation.Path == "" {
+			name := document.Type().Name()
+			return []string{name}, c.replaceValue(document, operation.Value, name, operation.Path)
+		}
+		return c.applyPointer(document, operation.Path, operation.Op == OpAdd, func(container reflect.Value, token string, name string) error {
+			return c.setChild(container, token, name, operation.Value, operation.Op == OpAdd, operation.Path)
+		})
+	case OpRemove:
+		if operation.Path == "" {
+			return nil, errors.New("Cannot remove the whole document")
+		}
+		return c.applyPointer(document, operation.Path, false, func(container reflect.Value, token string, name string) error {
+			return c.removeChild(container, token)
+		})
+	case OpMove, OpCopy:
+		if operation.From == "" || operation.Path == "" {
+			return nil, fmt.Errorf("Cannot %v the whole document", operation.Op)
+		}
+		if operation.Op == OpMove && operation.Path != operation.From && strings.HasPrefix(operation.Path, operation.From+"/") {
+			return nil, fmt.Errorf("Cannot move %v into one of its children %v", operation.From, operation.Path)
+		}
+
+		var value reflect.Value
+		if _, err := c.applyPointer(document, operation.From, false, func(container reflect.Value, token string, name string) error {
+			child, err := c.child(container, token)
+			if err != nil {
+				return err
+			}
+			value = deepCopy(child)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		pathsUpdated := make([]string, 0)
+		if operation.Op == OpMove {
+			removed, err := c.applyPointer(document, operation.From, false, func(container reflect.Value, token string, name string) error {
+				return c.removeChild(container, token)
+			})
+			if err != nil {
+				return nil, err
+			}
+			pathsUpdated = append(pathsUpdated, removed...)
+		}
+
+		added, err := c.applyPointer(document, operation.Path, true, func(container reflect.Value, token string, name string) error {
+			return c.setChild(container, token, name, value.Interface(), true, operation.Path)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(pathsUpdated, added...), nil
+	case OpTest:
+		if operation.Path == "" {
+			return nil, c.testValue(document, operation.Value, document.Type().Name(), operation.Path)
+		}
+		_, err := c.applyPointer(document, operation.Path, false, func(container reflect.Value, token string, name string) error {
+			child, err := c.child(container, token)
+			if err != nil {
+				return err
+			}
+			return c.testValue(child, operation.Value, name, operation.Path)
+		})
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("Unknown operation %v", operation.Op)
+}
+
+// setChild adds or replaces the value referenced by token inside container.
+// When insert is true, slice elements are inserted (- appends) and missing map keys are added.
+func (c *config) setChild(container reflect.Value, token string, name string, val interface{}, insert bool, path string) error {
+	switch container.Kind() {
+	case reflect.Slice:
+		i := container.Len()
+		if token != "-" || !insert {
+			var err error
+			i, err = strconv.Atoi(token)
+			if err != nil || i < 0 || i > container.Len() || (!insert && i == container.Len()) {
+				return ErrPathNotFound
+			}
+		}
+
+		elem := reflect.New(container.Type().Elem()).Elem()
+		if err := c.replaceValue(elem, val, name, path); err != nil {
+			return err
+		}
+		if !insert {
+			container.Index(i).Set(elem)
+			return nil
+		}
+		newSlice := reflect.MakeSlice(container.Type(), 0, container.Len()+1)
+		newSlice = reflect.AppendSlice(newSlice, container.Slice(0, i))
+		newSlice = reflect.Append(newSlice, elem)
+		newSlice = reflect.AppendSlice(newSlice, container.Slice(i, container.Len()))
+		container.Set(newSlice)
+		return nil
+	case reflect.Map:
+		if container.Type().Key().Kind() != reflect.String {
+			return ErrPathNotFound
+		}
+		if _, err := c.child(container, token); err != nil && !insert {
+			return err
+		}
+		elem := reflect.New(container.Type().Elem()).Elem()
+		if err := c.replaceValue(elem, val, name, path); err != nil {
+			return err
+		}
+		if container.IsNil() {
+			container.Set(reflect.MakeMap(container.Type()))
+		}
+		container.SetMapIndex(mapKey(container.Type(), token), elem)
+		return nil
+	}
+
+	child, err := c.child(container, token)
+	if err != nil {
+		return err
+	}
+	return c.forChild(container, token, val).replaceValue(child, val, name, path)
+}
+
+// removeChild removes the value referenced by token inside container.
+// Struct fields are reset to their zero value.
+func (c *config) removeChild(container reflect.Value, token string) error {
+	child, err := c.child(container, token)
+	if err != nil {
+		return err
+	}
+
+	switch container.Kind() {
+	case reflect.Slice:
+		i, _ := strconv.Atoi(token)
+		newSlice := reflect.MakeSlice(container.Type(), 0, container.Len()-1)
+		newSlice = reflect.AppendSlice(newSlice, container.Slice(0, i))
+		newSlice = reflect.AppendSlice(newSlice, container.Slice(i+1, container.Len()))
+		container.Set(newSlice)
+	case reflect.Map:
+		container.SetMapIndex(mapKey(container.Type(), token), reflect.Value{})
+	default:
+		child.Set(reflect.Zero(child.Type()))
+	}
+	return nil
+}
+
+// replaceValue replaces target with val. Objects replace nested structs as a whole,
+// and null resets pointers, interfaces, slices and maps to nil.
+func (c *config) replaceValue(target reflect.Value, val interface{}, name string, path string) error {
+	target.Set(reflect.Zero(target.Type()))
+	if val == nil {
+		switch target.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			return nil
+		}
+	}
+
+	// values copied from another path can be set directly when types match
+	if v := reflect.ValueOf(val); v.IsValid() && v.Type().AssignableTo(target.Type()) {
+		target.Set(v)
+		return nil
+	}
+
+	_, updateSuccess, err := c.updateValue(nil, target, val, name, path)
+	if !updateSuccess {
+		fieldError := newFieldError(path, "", target.Type(), val, err)
+		fieldError.Field = name
+		return fieldError
+	}
+	return err
+}
+
+// testValue checks that target is equal to val once val is converted to the target type
+func (c *config) testValue(target reflect.Value, val interface{}, name string, path string) error {
+	expected := reflect.New(target.Type()).Elem()
+	if err := c.replaceValue(expected, val, name, path); err != nil || !reflect.DeepEqual(target.Interface(), expected.Interface()) {
+		return &FieldError{
+			Path:   path,
+			Field:  name,
+			Type:   target.Type(),
+			Kind:   reflect.ValueOf(val).Kind(),
+			Value:  val,
+			Reason: ReasonTestFailed,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package gopartial
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type lineItem struct {
+	ID  string `json:"id"`
+	Qty int    `json:"qty"`
+}
+
+type order struct {
+	ID      string                 `json:"id" props:"readonly"`
+	Status  string                 `json:"status"`
+	Tags    []string               `json:"tags"`
+	Items   []lineItem             `json:"items"`
+	Billing *sub                   `json:"billing"`
+	Labels  map[string]string      `json:"labels"`
+	Extra   map[string]interface{} `json:"extra"`
+	Note    *int                   `json:"note"`
+}
+
+func newOrder() *order {
+	return &order{
+		ID:     "1",
+		Status: "open",
+		Tags:   []string{"a", "b"},
+		Items:  []lineItem{{ID: "x", Qty: 1}, {ID: "y", Qty: 2}},
+		Labels: map[string]string{"team": "x"},
+		Extra:  map[string]interface{}{"foo": map[string]interface{}{"bar": "baz"}},
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    func(o *order)
+		updated []string
+		wantErr bool
+	}{
+		{
+			name:    "add to slice",
+			patch:   `[{"op": "add", "path": "/tags/1", "value": "c"}]`,
+			want:    func(o *order) { o.Tags = []string{"a", "c", "b"} },
+			updated: []string{"Tags[1]"},
+		},
+		{
+			name:    "append to slice",
+			patch:   `[{"op": "add", "path": "/tags/-", "value": "c"}]`,
+			want:    func(o *order) { o.Tags = []string{"a", "b", "c"} },
+			updated: []string{"Tags[2]"},
+		},
+		{
+			name:    "add struct to slice",
+			patch:   `[{"op": "add", "path": "/items/0", "value": {"id": "z", "qty": 3}}]`,
+			want:    func(o *order) { o.Items = []lineItem{{ID: "z", Qty: 3}, {ID: "x", Qty: 1}, {ID: "y", Qty: 2}} },
+			updated: []string{"Items[0]"},
+		},
+		{
+			name:    "replace nested field in slice",
+			patch:   `[{"op": "replace", "path": "/items/1/qty", "value": 5}]`,
+			want:    func(o *order) { o.Items[1].Qty = 5 },
+			updated: []string{"Items[1].Qty"},
+		},
+		{
+			name:    "add through nil pointer",
+			patch:   `[{"op": "add", "path": "/billing/fielda", "value": "a"}]`,
+			want:    func(o *order) { o.Billing = &sub{FieldA: "a"} },
+			updated: []string{"Billing.FieldA"},
+		},
+		{
+			name:    "add map key",
+			patch:   `[{"op": "add", "path": "/labels/role", "value": "dev"}]`,
+			want:    func(o *order) { o.Labels["role"] = "dev" },
+			updated: []string{"Labels[role]"},
+		},
+		{
+			name:    "add nested map in interface",
+			patch:   `[{"op": "add", "path": "/extra/foo/qux", "value": 1}]`,
+			want:    func(o *order) { o.Extra["foo"] = map[string]interface{}{"bar": "baz", "qux": float64(1)} },
+			updated: []string{"Extra[foo][qux]"},
+		},
+		{
+			name:    "remove from slice and map",
+			patch:   `[{"op": "remove", "path": "/tags/0"}, {"op": "remove", "path": "/labels/team"}]`,
+			want:    func(o *order) { o.Tags = []string{"b"}; o.Labels = map[string]string{} },
+			updated: []string{"Tags[0]", "Labels[team]"},
+		},
+		{
+			name:    "remove struct field",
+			patch:   `[{"op": "remove", "path": "/status"}]`,
+			want:    func(o *order) { o.Status = "" },
+			updated: []string{"Status"},
+		},
+		{
+			name:    "replace with null",
+			patch:   `[{"op": "replace", "path": "/tags", "value": null}]`,
+			want:    func(o *order) { o.Tags = nil },
+			updated: []string{"Tags"},
+		},
+		{
+			name:    "move",
+			patch:   `[{"op": "move", "from": "/tags/0", "path": "/status"}]`,
+			want:    func(o *order) { o.Tags = []string{"b"}; o.Status = "a" },
+			updated: []string{"Tags[0]", "Status"},
+		},
+		{
+			name:    "copy",
+			patch:   `[{"op": "copy", "from": "/items/0", "path": "/items/-"}]`,
+			want:    func(o *order) { o.Items = append(o.Items, lineItem{ID: "x", Qty: 1}) },
+			updated: []string{"Items[2]"},
+		},
+		{
+			name:    "test then replace",
+			patch:   `[{"op": "test", "path": "/status", "value": "open"}, {"op": "replace", "path": "/status", "value": "closed"}]`,
+			want:    func(o *order) { o.Status = "closed" },
+			updated: []string{"Status"},
+		},
+		{
+			name:    "test struct value",
+			patch:   `[{"op": "test", "path": "/items/1", "value": {"id": "y", "qty": 2}}]`,
+			want:    func(o *order) {},
+			updated: []string{},
+		},
+		{
+			name:    "failed test",
+			patch:   `[{"op": "replace", "path": "/status", "value": "closed"}, {"op": "test", "path": "/status", "value": "open"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "replace missing slice index",
+			patch:   `[{"op": "replace", "path": "/tags/2", "value": "c"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "replace with wrong type",
+			patch:   `[{"op": "replace", "path": "/tags/0", "value": 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "replace readonly field",
+			patch:   `[{"op": "replace", "path": "/id", "value": "2"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "remove missing map key",
+			patch:   `[{"op": "remove", "path": "/labels/role"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "remove through nil pointer",
+			patch:   `[{"op": "remove", "path": "/billing/fielda"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "move into its own child",
+			patch:   `[{"op": "move", "from": "/items", "path": "/items/0"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown operation",
+			patch:   `[{"op": "increment", "path": "/status"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid path",
+			patch:   `[{"op": "replace", "path": "status", "value": "closed"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []Operation
+			require.NoError(t, json.Unmarshal([]byte(tt.patch), &operations))
+
+			dest := newOrder()
+			got, err := JSONPatch(dest, operations, "json", SkipConditions, Updaters)
+			if tt.wantErr {
+				require.Error(t, err)
+				// the patch is atomic, dest is left unchanged
+				require.Equal(t, newOrder(), dest)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.updated, got)
+			want := newOrder()
+			tt.want(want)
+			require.Equal(t, want, dest)
+		})
+	}
+}
+
+type treeNode struct {
+	Name   string      `json:"name"`
+	Parent *treeNode   `json:"parent"`
+	Kids   []*treeNode `json:"kids"`
+}
+
+func TestJSONPatchCyclicDest(t *testing.T) {
+	root := &treeNode{Name: "root"}
+	root.Kids = []*treeNode{{Name: "kid", Parent: root}}
+	kid := root.Kids[0]
+
+	var operations []Operation
+	require.NoError(t, json.Unmarshal([]byte(`[{"op": "replace", "path": "/kids/0/name", "value": "child"}]`), &operations))
+
+	got, err := JSONPatch(root, operations, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Kids[0].Name"}, got)
+	require.Equal(t, "child", kid.Name)
+	require.Same(t, kid, root.Kids[0])
+	require.Same(t, root, kid.Parent)
+}
+
+func TestJSONPatchKeepsPointers(t *testing.T) {
+	dest := newOrder()
+	dest.Billing = &sub{FieldA: "a"}
+	billing, labels, item := dest.Billing, dest.Labels, &dest.Items[1]
+
+	var operations []Operation
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"op": "replace", "path": "/status", "value": "closed"},
+		{"op": "replace", "path": "/items/1/qty", "value": 5}
+	]`), &operations))
+
+	_, err := JSONPatch(dest, operations, "json", SkipConditions, Updaters)
+	require.NoError(t, err)
+	// the fields left untouched keep their pointers and maps, the patched ones are updated in place
+	require.Same(t, billing, dest.Billing)
+	labels["team"] = "y"
+	require.Equal(t, "y", dest.Labels["team"])
+	require.Same(t, item, &dest.Items[1])
+	require.Equal(t, 5, item.Qty)
+}
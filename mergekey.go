@@ -0,0 +1,196 @@
+package gopartial
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DirectiveKey is the key of the directive of an object merged into a slice by key, see DirectiveDelete
+const DirectiveKey = "$patch"
+
+// DirectiveDelete removes the element matching an object merged into a slice by key, e.g. {"id": 2, "$patch": "delete"}
+const DirectiveDelete = "delete"
+
+// forMergeKey returns the config updating a field with the merge key of its patch tag, e.g. `patch:"mergeKey=id"`
+// for a slice of structs, and without one for the other fields
+func (c *config) forMergeKey(field *fieldPlan) *config {
+	fc := *c
+	fc.mergeKey = field.mergeKey
+	return &fc
+}
+
+// mergesByKey reports whether v is merged by key into a slice field of type t, see mergeSlice.
+// Arrays replace the field in a merge patch, as RFC 7396 requires.
+func (c *config) mergesByKey(t reflect.Type, v reflect.Value) bool {
+	return c.mergeKey != "" && !c.mergePatch && t.Kind() == reflect.Slice && isNestedStruct(t.Elem()) && isArray(v)
+}
+
+// mergeSlice merges the objects of v into the slice of structs field: an object is applied partially to
+// the element whose merge key has the same value, appended as a new element when none has, and removes
+// the element with the delete directive. Objects are reported at their index in v and the field is unchanged
+// when one is rejected. It returns false and ErrMergeKeyNotFound when the merge key is not a field of the elements.
+func (c *config) mergeSlice(fieldValue reflect.Value, v reflect.Value, name string, path string) (bool, error) {
+	typeOfElem := fieldValue.Type().Elem()
+	keyField, typeOfStruct, err := c.mergeKeyField(typeOfElem)
+	if err != nil {
+		return false, err
+	}
+
+	// the fields of the elements have their own merge keys
+	ec := *c
+	ec.mergeKey = ""
+
+	nval := reflect.MakeSlice(fieldValue.Type(), fieldValue.Len(), fieldValue.Len()+v.Len())
+	reflect.Copy(nval, fieldValue)
+	removed := make(map[int]bool)
+	var fieldErrors FieldErrors
+	for i := 0; i < v.Len(); i++ {
+		elemName, elemPath := fmt.Sprintf("%v[%v]", name, i), fmt.Sprintf("%v[%v]", path, i)
+		element := v.Index(i).Interface()
+		object, ok := element.(map[string]interface{})
+		if !ok {
+			if err := c.collect(&fieldErrors, newElementError(name, path, i, typeOfElem, element, nil)); err != nil {
+				return true, err
+			}
+			continue
+		}
+
+		index, keyValue, err := ec.matchElement(nval, removed, object, keyField, typeOfStruct, elemName, elemPath)
+		if err == nil {
+			if directive, ok := object[DirectiveKey]; ok {
+				err = removeElement(removed, index, directive, elemPath)
+			} else {
+				var updateSuccess bool
+				nval, updateSuccess, err = ec.mergeElement(nval, index, keyField, keyValue, object, elemName, elemPath)
+				if !updateSuccess {
+					err = newElementError(name, path, i, typeOfElem, element, err)
+				}
+			}
+		}
+		if err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return true, err
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return true, fieldErrors
+	}
+	fieldValue.Set(withoutElements(nval, removed))
+	return true, nil
+}
+
+// mergeKeyField returns the field of the merge key of the elements of type typeOfElem, structs or pointers
+// to structs, along with the struct type. It returns ErrMergeKeyNotFound when the merge key is not one of its fields.
+func (c *config) mergeKeyField(typeOfElem reflect.Type) (*fieldPlan, reflect.Type, error) {
+	typeOfStruct := typeOfElem
+	if typeOfStruct.Kind() == reflect.Ptr {
+		typeOfStruct = typeOfStruct.Elem()
+	}
+	// the merge key may be skipped, ids usually are read only
+	fields := c.planFor(typeOfStruct).fields
+	keyField := findField(fields, c.mergeKey, false)
+	if keyField == nil && c.caseInsensitive {
+		keyField = findField(fields, c.mergeKey, true)
+	}
+	if keyField == nil {
+		return nil, typeOfStruct, ErrMergeKeyNotFound
+	}
+	return keyField, typeOfStruct, nil
+}
+
+// withoutElements returns slice without the elements at the removed indexes
+func withoutElements(slice reflect.Value, removed map[int]bool) reflect.Value {
+	if len(removed) == 0 {
+		return slice
+	}
+	kept := reflect.MakeSlice(slice.Type(), 0, slice.Len()-len(removed))
+	for i := 0; i < slice.Len(); i++ {
+		if !removed[i] {
+			kept = reflect.Append(kept, slice.Index(i))
+		}
+	}
+	return kept
+}
+
+// matchElement returns the index of the element of slice whose merge key equals the one of object,
+// -1 when object has no key or none matches, along with the key converted to the type of the key field,
+// invalid when object has no key
+func (c *config) matchElement(slice reflect.Value, removed map[int]bool, object map[string]interface{}, keyField *fieldPlan, typeOfStruct reflect.Type, name string, path string) (int, reflect.Value, error) {
+	key, val, ok := c.lookup(object, keyField.key)
+	if !ok || val == nil {
+		return -1, reflect.Value{}, nil
+	}
+
+	// the key is converted to the type of the key field to be compared
+	keyValue := reflect.New(keyField.field.Type).Elem()
+	if _, updateSuccess, err := c.updateValue(nil, keyValue, val, joinPath(name, keyField.field.Name), joinPath(path, key)); !updateSuccess {
+		fieldError := newFieldError(joinPath(path, key), key, keyField.field.Type, val, err)
+		fieldError.Struct = typeOfStruct.Name()
+		fieldError.Field = keyField.field.Name
+		return -1, reflect.Value{}, fieldError
+	}
+
+	for i := 0; i < slice.Len(); i++ {
+		elem := slice.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		if !removed[i] && reflect.DeepEqual(elem.Field(keyField.index).Interface(), keyValue.Interface()) {
+			return i, keyValue, nil
+		}
+	}
+	return -1, keyValue, nil
+}
+
+// removeElement marks the element at index as removed by directive, the delete directive.
+// An object matching no element removes nothing.
+func removeElement(removed map[int]bool, index int, directive interface{}, path string) error {
+	if directive != DirectiveDelete {
+		return newFieldError(joinPath(path, DirectiveKey), DirectiveKey, nil, directive, ErrUnknownDirective)
+	}
+	if index >= 0 {
+		removed[index] = true
+	}
+	return nil
+}
+
+// mergeElement applies object partially to a copy of the element of slice at index,
+// or to a new element appended to slice when index is -1, whose key field is set to keyValue
+// even when it is read only, so that later merges match it. It returns the updated slice,
+// false when object is rejected, and the errors of the fields of the element.
+func (c *config) mergeElement(slice reflect.Value, index int, keyField *fieldPlan, keyValue reflect.Value, object map[string]interface{}, name string, path string) (reflect.Value, bool, error) {
+	typeOfElem := slice.Type().Elem()
+	elem := reflect.New(typeOfElem).Elem()
+	if index >= 0 {
+		elem.Set(slice.Index(index))
+		// the elements pointed to are shared with the field, update a copy
+		if elem.Kind() == reflect.Ptr && !elem.IsNil() {
+			copied := reflect.New(typeOfElem.Elem())
+			copied.Elem().Set(elem.Elem())
+			elem.Set(copied)
+		}
+	}
+
+	_, updateSuccess, err := c.updateValue(nil, elem, object, name, path)
+	if !updateSuccess || err != nil {
+		return slice, updateSuccess, err
+	}
+
+	if index < 0 {
+		if keyValue.IsValid() {
+			element := elem
+			if element.Kind() == reflect.Ptr {
+				element = element.Elem()
+			}
+			element.Field(keyField.index).Set(keyValue)
+		}
+		return reflect.Append(slice, elem), true, nil
+	}
+	slice.Index(index).Set(elem)
+	return slice, true, nil
+}
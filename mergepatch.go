@@ -0,0 +1,120 @@
+package gopartial
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergePatch applies a JSON Merge Patch document (RFC 7396) to destination object
+// (Must be a pointer to a struct). It behaves like PartialUpdate with the merge patch rules:
+// nested objects are merged recursively, null resets the field to its zero value,
+// arrays replace the existing slice and null removes keys from map fields.
+// Returns list of struct field names that was successfully updated.
+func MergePatch(dest interface{}, patch map[string]interface{}, tagName string, skipConditions []func(reflect.StructField) bool, updaters []func(reflect.Value, reflect.Value) bool) ([]string, error) {
+	return newConfig(append(positional(tagName, skipConditions, updaters), WithMergePatch())...).update(dest, patch)
+}
+
+// mergeValue applies the merge patch rules that differ from a partial update:
+// null resets the field to its zero value, and objects are merged into map and interface{} fields.
+// handled is false when val must be assigned the same way as in a partial update.
+func (c *config) mergeValue(fieldValue reflect.Value, val interface{}, name string, path string) (handled bool, updateSuccess bool, err error) {
+	if val == nil {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, true, nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Interface:
+		var target interface{}
+		if !fieldValue.IsNil() {
+			target = fieldValue.Interface()
+		}
+		merged := reflect.ValueOf(mergeJSON(target, val))
+		if !merged.Type().AssignableTo(fieldValue.Type()) {
+			return false, false, nil
+		}
+		fieldValue.Set(merged)
+		return true, true, nil
+	case reflect.Map:
+		patch, ok := val.(map[string]interface{})
+		if !ok || fieldValue.Type().Key().Kind() != reflect.String {
+			return false, false, nil
+		}
+		return true, true, c.mergeMap(fieldValue, patch, name, path)
+	}
+
+	return false, false, nil
+}
+
+// mergeMap merges patch into a map field with string keys,
+// removing the keys whose patch value is null
+func (c *config) mergeMap(fieldValue reflect.Value, patch map[string]interface{}, name string, path string) error {
+	typeOfMap := fieldValue.Type()
+	if fieldValue.IsNil() {
+		fieldValue.Set(reflect.MakeMap(typeOfMap))
+	}
+
+	var fieldErrors FieldErrors
+	for k, val := range patch {
+		key := reflect.ValueOf(k).Convert(typeOfMap.Key())
+		if val == nil {
+			fieldValue.SetMapIndex(key, reflect.Value{})
+			continue
+		}
+
+		// map elements are not addressable, update a copy and put it back
+		elem := reflect.New(typeOfMap.Elem()).Elem()
+		if existing := fieldValue.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		var elemName, elemPath string
+		if isComposite(val) {
+			elemName, elemPath = fmt.Sprintf("%v[%v]", name, k), joinPath(path, k)
+		}
+		_, updateSuccess, err := c.updateValue(nil, elem, val, elemName, elemPath)
+		if !updateSuccess {
+			fieldError := newFieldError(joinPath(path, k), k, typeOfMap.Elem(), val, err)
+			fieldError.Field = fmt.Sprintf("%v[%v]", name, k)
+			err = fieldError
+		}
+		if err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return err
+			}
+		}
+		if updateSuccess {
+			fieldValue.SetMapIndex(key, elem)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
+
+// mergeJSON merges patch into target as defined by the MergePatch function of RFC 7396,
+// where both are decoded JSON values. target is not modified.
+func mergeJSON(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	result := make(map[string]interface{})
+	if targetObject, ok := target.(map[string]interface{}); ok {
+		for k, v := range targetObject {
+			result[k] = v
+		}
+	}
+
+	for k, v := range patchObject {
+		if v == nil {
+			delete(result, k)
+		} else {
+			result[k] = mergeJSON(result[k], v)
+		}
+	}
+
+	return result
+}
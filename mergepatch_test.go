@@ -0,0 +1,100 @@
+package gopartial
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeDocument struct {
+	A interface{} `json:"a"`
+	B interface{} `json:"b"`
+	C interface{} `json:"c"`
+	E interface{} `json:"e"`
+}
+
+// TestMergePatchRFC7396 runs the examples of RFC 7396 Appendix A.
+// Examples whose document is not an object are nested under the "a" key,
+// since the destination of MergePatch is always a struct.
+func TestMergePatchRFC7396(t *testing.T) {
+	tests := []struct {
+		original string
+		patch    string
+		result   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"a":["a","b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+		{`{"a":{"a":"b"}}`, `{"a":["c"]}`, `{"a":["c"]}`},
+		{`{"a":{"a":"foo"}}`, `{"a":null}`, `{}`},
+		{`{"a":{"a":"foo"}}`, `{"a":"bar"}`, `{"a":"bar"}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{"a":[1,2]}`, `{"a":{"a":"b","c":null}}`, `{"a":{"a":"b"}}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.original+" "+tt.patch, func(t *testing.T) {
+			var dest, want mergeDocument
+			var patch map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.original), &dest))
+			require.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+			require.NoError(t, json.Unmarshal([]byte(tt.result), &want))
+
+			_, err := MergePatch(&dest, patch, "json", SkipConditions, Updaters)
+			require.NoError(t, err)
+			require.Equal(t, want, dest)
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	type model struct {
+		Name    string            `json:"name"`
+		Nick    null.String       `json:"nick"`
+		Age     *int              `json:"age"`
+		Address *sub              `json:"address"`
+		Labels  map[string]string `json:"labels"`
+		Tags    []string          `json:"tags"`
+	}
+
+	age := 20
+	dest := &model{
+		Name:    "John",
+		Nick:    null.StringFrom("Johnny"),
+		Age:     &age,
+		Address: &sub{FieldA: "a", FieldB: "b"},
+		Labels:  map[string]string{"team": "x", "role": "dev"},
+		Tags:    []string{"a", "b"},
+	}
+
+	var patch map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"nick": null,
+		"age": null,
+		"address": {"fielda": "c"},
+		"labels": {"team": null, "level": "senior"},
+		"tags": ["c"]
+	}`), &patch))
+
+	got, err := MergePatch(dest, patch, "json", SkipConditions, AllUpdaters)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Nick", "Age", "Address.FieldA", "Labels", "Tags"}, got)
+	require.Equal(t, &model{
+		Name:    "John",
+		Address: &sub{FieldA: "c", FieldB: "b"},
+		Labels:  map[string]string{"role": "dev", "level": "senior"},
+		Tags:    []string{"c"},
+	}, dest)
+
+	_, err = MergePatch(dest, map[string]interface{}{"labels": map[string]interface{}{"team": 1}}, "json", SkipConditions, AllUpdaters)
+	require.Error(t, err)
+}
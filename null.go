@@ -0,0 +1,80 @@
+package gopartial
+
+import (
+	"reflect"
+	"strings"
+)
+
+// NullPolicy tells what null does to a field that cannot be nil, such as a string, a number or a struct,
+// when no updater handles it. Pointers, slices, maps and interfaces are always set to nil by null.
+type NullPolicy int
+
+// Null policies
+const (
+	// NullNotAllowed rejects null with ReasonNullNotAllowed, the default
+	NullNotAllowed NullPolicy = iota + 1
+	// NullAsZero resets the field to its zero value
+	NullAsZero
+)
+
+// PatchTag is the struct tag holding the options of a field, e.g. `patch:"null=zero"`
+const PatchTag = "patch"
+
+// WithNullPolicy sets what null does to the fields that cannot be nil, NullNotAllowed by default.
+// A field can override it with its patch tag: `patch:"null=zero"` or `patch:"null=reject"`.
+func WithNullPolicy(policy NullPolicy) Option {
+	return func(c *config) {
+		c.null = policy
+	}
+}
+
+// patchOption returns the value of an option of a patch tag, e.g. zero for null in "null=zero"
+func patchOption(tag string, name string) string {
+	for _, option := range strings.Split(tag, ",") {
+		if kv := strings.SplitN(option, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == name {
+			return strings.TrimSpace(kv[1])
+		}
+	}
+	return ""
+}
+
+// nullPolicyOf returns the null policy of the patch tag of field, 0 when it has none
+func nullPolicyOf(field reflect.StructField) NullPolicy {
+	switch patchOption(field.Tag.Get(PatchTag), "null") {
+	case "zero":
+		return NullAsZero
+	case "reject":
+		return NullNotAllowed
+	}
+	return 0
+}
+
+// forNull returns the config updating a field with null, c itself unless the field overrides its null policy
+func (c *config) forNull(field *fieldPlan) *config {
+	if field.null == 0 || field.null == c.null {
+		return c
+	}
+	fc := *c
+	fc.null = field.null
+	return &fc
+}
+
+// forChild returns the config updating the value referenced by token inside container with val,
+// the one of the struct field for null, see forNull
+func (c *config) forChild(container reflect.Value, token string, val interface{}) *config {
+	if val == nil && container.Kind() == reflect.Struct {
+		if field, err := c.fieldByKey(container, token); err == nil {
+			return c.forNull(field)
+		}
+	}
+	return c
+}
+
+// canBeNil reports whether null sets a field of kind to nil
+func canBeNil(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,442 @@
+package gopartial
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Operators of the objects editing slice fields with WithOperators, e.g. {"tags": {"$push": ["a"]}}.
+// Their operand is an array of elements, or a single element.
+const (
+	// OperatorPush appends the elements
+	OperatorPush = "$push"
+	// OperatorPrepend inserts the elements at the start
+	OperatorPrepend = "$prepend"
+	// OperatorPull removes every element equal to one of the elements, matched by merge key in a slice of structs with one
+	OperatorPull = "$pull"
+	// OperatorAddToSet appends the elements that are not in the slice yet
+	OperatorAddToSet = "$addToSet"
+)
+
+// Operators evaluated against the current value of any field with WithOperators, e.g. {"views": {"$inc": 1}}
+const (
+	// OperatorInc adds a number to a number field, null counting as 0
+	OperatorInc = "$inc"
+	// OperatorMul multiplies a number field by a number, null counting as 0
+	OperatorMul = "$mul"
+	// OperatorMin sets the field to the value when it is lower, or when the field is null
+	OperatorMin = "$min"
+	// OperatorMax sets the field to the value when it is greater, or when the field is null
+	OperatorMax = "$max"
+	// OperatorUnset resets the field to its zero value, whatever the operand
+	OperatorUnset = "$unset"
+	// OperatorCurrentDate sets a time field to the current time, the operand must be true
+	OperatorCurrentDate = "$currentDate"
+)
+
+// sliceOperators are applied in this order when an object has several of them
+var sliceOperators = []string{OperatorPull, OperatorPush, OperatorPrepend, OperatorAddToSet}
+
+// fieldOperators are applied in this order when an object has several of them
+var fieldOperators = []string{OperatorUnset, OperatorInc, OperatorMul, OperatorMin, OperatorMax, OperatorCurrentDate}
+
+// isOperators reports whether any key of object is an operator, a key starting with $
+func isOperators(object map[string]interface{}) bool {
+	for key := range object {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// updateOperators applies the operators of object to the field with WithOperators: the slice operators
+// to a slice field, unless object has any of the field operators, and the field operators otherwise.
+// handled is false without WithOperators, object is then assigned like any other object,
+// and updated is false when the operators leave the field as it was.
+func (c *config) updateOperators(fieldValue reflect.Value, operators map[string]interface{}, name string, path string) (handled bool, updated bool, err error) {
+	if !c.operators {
+		return false, false, nil
+	}
+
+	fieldOperator := false
+	for _, operator := range fieldOperators {
+		if _, ok := operators[operator]; ok {
+			fieldOperator = true
+		}
+	}
+	if fieldValue.Kind() == reflect.Slice && !fieldOperator {
+		return true, true, c.updateSliceOperators(fieldValue, operators, name, path)
+	}
+	updated, err = c.updateFieldOperators(fieldValue, operators, name, path)
+	return true, updated, err
+}
+
+// updateSliceOperators applies the operators of object to a copy of the slice field that replaces it
+// once every operand is converted like the elements of an array. The field is unchanged when
+// an operator is unknown or an element of an operand is rejected.
+func (c *config) updateSliceOperators(fieldValue reflect.Value, operators map[string]interface{}, name string, path string) error {
+	var fieldErrors FieldErrors
+	if err := c.collect(&fieldErrors, unknownOperators(operators, sliceOperators, path)); err != nil {
+		return err
+	}
+
+	nval := reflect.MakeSlice(fieldValue.Type(), fieldValue.Len(), fieldValue.Len())
+	reflect.Copy(nval, fieldValue)
+	for _, operator := range sliceOperators {
+		operand, ok := operators[operator]
+		if !ok {
+			continue
+		}
+		v := reflect.ValueOf(operand)
+		if !isArray(v) {
+			v = reflect.ValueOf([]interface{}{operand})
+		}
+
+		// the elements of a slice with a merge key are pulled by key, e.g. {"$pull": {"id": 1}}
+		if operator == OperatorPull && c.mergesByKey(fieldValue.Type(), v) {
+			pulled, err := c.pullByKey(nval, v, joinPath(name, operator), joinPath(path, operator))
+			if err != nil {
+				if err := c.collect(&fieldErrors, err); err != nil {
+					return err
+				}
+				continue
+			}
+			nval = pulled
+			continue
+		}
+
+		elements := reflect.New(fieldValue.Type()).Elem()
+		if err := c.updateSlice(elements, v, joinPath(name, operator), joinPath(path, operator)); err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return err
+			}
+			continue
+		}
+		nval = applySliceOperator(operator, nval, elements)
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	fieldValue.Set(nval)
+	return nil
+}
+
+// updateFieldOperators applies the operators of object to a copy of the field that replaces it once
+// every operator succeeds. The results are assigned like values, so the updaters reject a number that
+// does not fit in the field with ReasonOverflow, and set time fields.
+// It returns false when no operator changed the field, e.g. a $min above the current value.
+func (c *config) updateFieldOperators(fieldValue reflect.Value, operators map[string]interface{}, name string, path string) (bool, error) {
+	var fieldErrors FieldErrors
+	if err := c.collect(&fieldErrors, unknownOperators(operators, fieldOperators, path)); err != nil {
+		return false, err
+	}
+
+	nval := reflect.New(fieldValue.Type()).Elem()
+	nval.Set(fieldValue)
+	updated := false
+	for _, operator := range fieldOperators {
+		operand, ok := operators[operator]
+		if !ok {
+			continue
+		}
+		changed, err := c.applyFieldOperator(operator, nval, operand)
+		if err != nil {
+			fieldError := newFieldError(joinPath(path, operator), operator, fieldValue.Type(), operand, err)
+			fieldError.Field = joinPath(name, operator)
+			if err := c.collect(&fieldErrors, fieldError); err != nil {
+				return false, err
+			}
+			continue
+		}
+		updated = updated || changed
+	}
+
+	if len(fieldErrors) > 0 {
+		return false, fieldErrors
+	}
+	fieldValue.Set(nval)
+	return updated, nil
+}
+
+// applyFieldOperator applies operator with operand to fieldValue. It returns false when $min or $max
+// keep the current value, and the error of the updater rejecting the result, or the Reason of an operand
+// that does not apply to the field, e.g. a number with a fraction for an integer field.
+func (c *config) applyFieldOperator(operator string, fieldValue reflect.Value, operand interface{}) (bool, error) {
+	v := reflect.ValueOf(operand)
+	switch operator {
+	case OperatorUnset:
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, nil
+	case OperatorCurrentDate:
+		if operand != true || !isTimeType(fieldValue.Type()) {
+			return false, mismatch(operand)
+		}
+		// a RFC 3339 time is set by the updaters of every time type
+		v = reflect.ValueOf(time.Now().Format(time.RFC3339Nano))
+	case OperatorInc, OperatorMul:
+		if !isNumberType(fieldValue.Type()) || !isNumberKind(v.Kind()) {
+			return false, mismatch(operand)
+		}
+		// integers are not truncated, e.g. by {"$inc": 1.5}
+		if isIntegerType(fieldValue.Type()) && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) && v.Float() != math.Trunc(v.Float()) {
+			return false, ReasonTypeMismatch
+		}
+		current, _ := currentValue(fieldValue)
+		result, reason := arithmetic(operator, current, v)
+		if reason != "" {
+			return false, reason
+		}
+		v = result
+	case OperatorMin, OperatorMax:
+		if operand == nil {
+			return false, ReasonNullNotAllowed
+		}
+		// the operand is converted to the field type to be compared
+		converted := reflect.New(fieldValue.Type()).Elem()
+		if updateSuccess, err := c.assign(converted, v); !updateSuccess {
+			if err == nil {
+				return false, reasonOf(fieldValue.Type(), operand)
+			}
+			return false, err
+		}
+		current, ok := currentValue(fieldValue)
+		if !ok {
+			return false, ReasonTypeMismatch
+		}
+		if current.IsValid() {
+			value, _ := currentValue(converted)
+			order, comparable := compare(value, current)
+			if !comparable {
+				return false, ReasonTypeMismatch
+			}
+			if operator == OperatorMin && order >= 0 || operator == OperatorMax && order <= 0 {
+				return false, nil
+			}
+		}
+		fieldValue.Set(converted)
+		return true, nil
+	}
+
+	if updateSuccess, err := c.assign(fieldValue, v); !updateSuccess {
+		if err == nil {
+			return false, reasonOf(fieldValue.Type(), v.Interface())
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// currentValue returns the value of a field holding a number, a string or a time: the field itself,
+// the value pointed to, or the value of a nullable type. It is invalid when the field is nil or null,
+// and ok is false for the other fields.
+func currentValue(fieldValue reflect.Value) (reflect.Value, bool) {
+	v := fieldValue
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, isComparable(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	if isNullWrapper(v.Type()) {
+		v = v.Field(0)
+	}
+	if isSQLNull(v.Type()) {
+		if !v.Field(1).Bool() {
+			return reflect.Value{}, isComparable(v.Type().Field(0).Type)
+		}
+		v = v.Field(0)
+	}
+	return v, isComparable(v.Type())
+}
+
+// isComparable reports whether the values of t are ordered by compare
+func isComparable(t reflect.Type) bool {
+	return isNumberKind(t.Kind()) || t.Kind() == reflect.String || t == typeOfTime
+}
+
+// compare returns -1, 0 or 1 when a is lower than, equal to or greater than b, two values of the same type.
+// comparable is false for the types other than numbers, strings and times.
+func compare(a reflect.Value, b reflect.Value) (order int, comparable bool) {
+	switch {
+	case a.Type() == typeOfTime:
+		x, y := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case x.Before(y):
+			return -1, true
+		case x.After(y):
+			return 1, true
+		}
+		return 0, true
+	case a.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	}
+
+	switch i, u, f, kind := numberOf(a); kind {
+	case signedNumber:
+		return big.NewInt(i).Cmp(big.NewInt(b.Int())), true
+	case unsignedNumber:
+		return new(big.Int).SetUint64(u).Cmp(new(big.Int).SetUint64(b.Uint())), true
+	case floatNumber:
+		return big.NewFloat(f).Cmp(big.NewFloat(b.Float())), true
+	}
+	return 0, false
+}
+
+// arithmetic returns the result of operator, OperatorInc or OperatorMul, on current and operand, two numbers.
+// A null current value counts as 0. The result is a float64 when any of them is a float, and an int64 or uint64
+// otherwise, rejected with ReasonOverflow when it does not fit in any of them.
+func arithmetic(operator string, current reflect.Value, operand reflect.Value) (reflect.Value, Reason) {
+	ci, cu, cf, ckind := numberOf(current)
+	oi, ou, of, okind := numberOf(operand)
+	if ckind == floatNumber || okind == floatNumber {
+		x, _ := toFloat(ci, cu, cf, ckind, 64)
+		y, _ := toFloat(oi, ou, of, okind, 64)
+		result := x + y
+		if operator == OperatorMul {
+			result = x * y
+		}
+		if math.IsInf(result, 0) || math.IsNaN(result) {
+			return reflect.Value{}, ReasonOverflow
+		}
+		return reflect.ValueOf(result), ""
+	}
+
+	x, y := bigInt(ci, cu, ckind), bigInt(oi, ou, okind)
+	result := new(big.Int).Add(x, y)
+	if operator == OperatorMul {
+		result = new(big.Int).Mul(x, y)
+	}
+	switch {
+	case result.IsInt64():
+		return reflect.ValueOf(result.Int64()), ""
+	case result.IsUint64():
+		return reflect.ValueOf(result.Uint64()), ""
+	}
+	return reflect.Value{}, ReasonOverflow
+}
+
+// bigInt returns an integer, see numberOf, as a big.Int, 0 for null
+func bigInt(i int64, u uint64, kind numberKind) *big.Int {
+	if kind == unsignedNumber {
+		return new(big.Int).SetUint64(u)
+	}
+	return big.NewInt(i)
+}
+
+// unknownOperators returns the errors of the keys of object that are not one of operators, nil when there is none
+func unknownOperators(object map[string]interface{}, operators []string, path string) error {
+	var unknown []string
+	for key := range object {
+		if !hasOperator(operators, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	fieldErrors := make(FieldErrors, len(unknown))
+	for i, key := range unknown {
+		fieldErrors[i] = newFieldError(joinPath(path, key), key, nil, object[key], ErrUnknownOperator)
+	}
+	if len(fieldErrors) == 1 {
+		return fieldErrors[0]
+	}
+	return fieldErrors
+}
+
+// hasOperator reports whether operator is one of operators
+func hasOperator(operators []string, operator string) bool {
+	for _, known := range operators {
+		if known == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// applySliceOperator returns slice edited by operator with elements, a slice of the same type
+func applySliceOperator(operator string, slice reflect.Value, elements reflect.Value) reflect.Value {
+	switch operator {
+	case OperatorPush:
+		return reflect.AppendSlice(slice, elements)
+	case OperatorPrepend:
+		return reflect.AppendSlice(elements, slice)
+	case OperatorPull:
+		kept := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+		for i := 0; i < slice.Len(); i++ {
+			if !containsElement(elements, slice.Index(i)) {
+				kept = reflect.Append(kept, slice.Index(i))
+			}
+		}
+		return kept
+	case OperatorAddToSet:
+		for i := 0; i < elements.Len(); i++ {
+			if !containsElement(slice, elements.Index(i)) {
+				slice = reflect.Append(slice, elements.Index(i))
+			}
+		}
+	}
+	return slice
+}
+
+// pullByKey returns a copy of slice, a slice of structs with a merge key, without the elements whose key
+// matches the key of one of the objects of v, see matchElement. Objects without a key remove nothing.
+func (c *config) pullByKey(slice reflect.Value, v reflect.Value, name string, path string) (reflect.Value, error) {
+	typeOfElem := slice.Type().Elem()
+	keyField, typeOfStruct, err := c.mergeKeyField(typeOfElem)
+	if err != nil {
+		return slice, newFieldError(path, OperatorPull, slice.Type(), v.Interface(), err)
+	}
+
+	removed := make(map[int]bool)
+	var fieldErrors FieldErrors
+	for i := 0; i < v.Len(); i++ {
+		element := v.Index(i).Interface()
+		object, ok := element.(map[string]interface{})
+		if !ok {
+			if err := c.collect(&fieldErrors, newElementError(name, path, i, typeOfElem, element, nil)); err != nil {
+				return slice, err
+			}
+			continue
+		}
+
+		elemName, elemPath := fmt.Sprintf("%v[%v]", name, i), fmt.Sprintf("%v[%v]", path, i)
+		for {
+			index, _, err := c.matchElement(slice, removed, object, keyField, typeOfStruct, elemName, elemPath)
+			if err != nil {
+				if err := c.collect(&fieldErrors, err); err != nil {
+					return slice, err
+				}
+				break
+			}
+			if index < 0 {
+				break
+			}
+			removed[index] = true
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return slice, fieldErrors
+	}
+	return withoutElements(slice, removed), nil
+}
+
+// containsElement reports whether slice has an element deeply equal to elem
+func containsElement(slice reflect.Value, elem reflect.Value) bool {
+	for i := 0; i < slice.Len(); i++ {
+		if reflect.DeepEqual(slice.Index(i).Interface(), elem.Interface()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package gopartial
+
+import (
+	"reflect"
+)
+
+// Option configures an update, see Apply
+type Option func(*config)
+
+// WithTagName sets the struct tag holding the partial keys, "json" by default.
+// An empty tag name looks the fields up by their name.
+func WithTagName(tagName string) Option {
+	return func(c *config) {
+		c.tagName = tagName
+	}
+}
+
+// WithSkipConditions sets the skip conditions, SkipConditions by default
+func WithSkipConditions(skipConditions ...func(reflect.StructField) bool) Option {
+	return func(c *config) {
+		c.skipConditions = skipConditions
+	}
+}
+
+// WithUpdaters sets the updaters, Updaters by default
+func WithUpdaters(updaters ...func(reflect.Value, reflect.Value) bool) Option {
+	return func(c *config) {
+		c.updaters = updaters
+	}
+}
+
+// WithCheckedUpdaters sets the updaters telling why a value is rejected, tried in order before
+// the updaters returning a bool, none by default. Use CheckedUpdaters instead of Updaters:
+//
+//	gopartial.Apply(&dest, partial, gopartial.WithUpdaters(), gopartial.WithCheckedUpdaters(gopartial.CheckedUpdaters...))
+func WithCheckedUpdaters(updaters ...Updater) Option {
+	// the option keeps its own copy of the slice given with updaters...
+	updaters = append([]Updater(nil), updaters...)
+	return func(c *config) {
+		c.checkedUpdaters = updaters
+	}
+}
+
+// WithAtomic makes the update all or nothing, like AtomicPartialUpdate
+func WithAtomic() Option {
+	return func(c *config) {
+		c.atomic = true
+	}
+}
+
+// WithCollectErrors keeps going after a field fails and reports every failed field,
+// like ExhaustivePartialUpdate
+func WithCollectErrors() Option {
+	return func(c *config) {
+		c.collectErrors = true
+	}
+}
+
+// WithMergePatch applies the JSON Merge Patch rules, like MergePatch
+func WithMergePatch() Option {
+	return func(c *config) {
+		c.mergePatch = true
+	}
+}
+
+// WithOperators evaluates the objects of operators against the current value of the fields,
+// e.g. {"views": {"$inc": 1}} or {"tags": {"$push": ["a"]}}, instead of assigning them.
+// See OperatorInc and OperatorPush.
+func WithOperators() Option {
+	return func(c *config) {
+		c.operators = true
+	}
+}
+
+// newConfig returns the config made of the options applied over the defaults:
+// the "json" tag name, SkipConditions and Updaters
+func newConfig(opts ...Option) *config {
+	c := &config{
+		tagName:        "json",
+		skipConditions: SkipConditions,
+		updaters:       Updaters,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// positional returns the options of the positional arguments of PartialUpdate
+func positional(tagName string, skipConditions []func(reflect.StructField) bool, updaters []func(reflect.Value, reflect.Value) bool) []Option {
+	return []Option{
+		WithTagName(tagName),
+		WithSkipConditions(skipConditions...),
+		WithUpdaters(updaters...),
+	}
+}
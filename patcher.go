@@ -0,0 +1,104 @@
+package gopartial
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Patcher applies partial updates with a configuration set once by its options.
+// It owns its plan cache, where the skip conditions are evaluated once per struct field,
+// and it is safe for concurrent use.
+type Patcher struct {
+	config config
+}
+
+// NewPatcher returns a Patcher configured by the options over the defaults of Apply.
+// The skip conditions, updaters, checked updaters and registry are copied, later changes to them or to the
+// package-level SkipConditions, Updaters and AllUpdaters slices do not affect the Patcher.
+func NewPatcher(opts ...Option) *Patcher {
+	c := newConfig(opts...)
+	c.skipConditions = append([]func(reflect.StructField) bool(nil), c.skipConditions...)
+	c.updaters = append([]func(reflect.Value, reflect.Value) bool(nil), c.updaters...)
+	c.checkedUpdaters = append([]Updater(nil), c.checkedUpdaters...)
+	if c.registry != nil {
+		c.registry = c.registry.Clone()
+	}
+	c.plans = &planCache{}
+	c.cachedSkips = true
+	return &Patcher{config: *c}
+}
+
+// Apply updates dest (Must be a pointer to a struct) from partial, see the Apply function
+func (p *Patcher) Apply(dest interface{}, partial map[string]interface{}) (Result, error) {
+	fieldsUpdated, err := p.config.update(dest, partial)
+	return Result{Updated: fieldsUpdated}, err
+}
+
+// JSONPatch applies a list of JSON Patch operations to dest, see the JSONPatch function
+func (p *Patcher) JSONPatch(dest interface{}, operations []Operation) ([]string, error) {
+	return p.config.jsonPatch(dest, operations)
+}
+
+// WithStrict rejects the keys that do not match a field, and the keys of the fields matching
+// a skip condition, instead of ignoring them. The FieldError reasons are ReasonUnknownPath and ReasonReadOnly.
+func WithStrict() Option {
+	return func(c *config) {
+		c.strict = true
+	}
+}
+
+// WithCaseInsensitiveKeys matches the keys to the fields regardless of case,
+// like encoding/json does. An exact match is preferred.
+func WithCaseInsensitiveKeys() Option {
+	return func(c *config) {
+		c.caseInsensitive = true
+	}
+}
+
+// lookup returns the key of partial matching the key of a field along with its value.
+// An exact match is preferred, then the first matching key in sorted order regardless of case.
+func (c *config) lookup(partial map[string]interface{}, fieldKey string) (string, interface{}, bool) {
+	if val, ok := partial[fieldKey]; ok {
+		return fieldKey, val, true
+	}
+	if !c.caseInsensitive {
+		return "", nil, false
+	}
+
+	found := false
+	var match string
+	for key := range partial {
+		if strings.EqualFold(key, fieldKey) && (!found || key < match) {
+			found, match = true, key
+		}
+	}
+	return match, partial[match], found
+}
+
+// checkKeys returns the errors of the keys of partial that don't match a settable field of valueOfDest,
+// path keys are checked when they are applied
+func (c *config) checkKeys(valueOfDest reflect.Value, partial map[string]interface{}, path string) error {
+	keys := make([]string, 0, len(partial))
+	for key := range partial {
+		if !isPathKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	// report the keys in a stable order
+	sort.Strings(keys)
+
+	var fieldErrors FieldErrors
+	for _, key := range keys {
+		if _, err := c.fieldByKey(valueOfDest, key); err != nil {
+			if err := c.collect(&fieldErrors, newPathError(joinPath(path, key), key, partial[key], err)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package gopartial
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatcher(t *testing.T) {
+	t.Run("Defaults like Apply", func(t *testing.T) {
+		dest := destination{}
+		result, err := NewPatcher().Apply(&dest, map[string]interface{}{"field0": "foo", "field1": "foo", "field11": map[string]interface{}{"fielda": "bar"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Field1", "Field11.FieldA"}, result.Updated)
+		require.Equal(t, destination{Field1: "foo", Field11: sub{FieldA: "bar"}}, dest)
+	})
+
+	t.Run("Updaters are copied", func(t *testing.T) {
+		patcher := NewPatcher(WithUpdaters(TimeUpdater))
+		dest := destination{}
+		_, err := patcher.Apply(&dest, map[string]interface{}{"field9": "2017-11-22T20:30:26.716Z"})
+		require.NoError(t, err)
+
+		defaultPatcher := NewPatcher()
+		updaters := Updaters
+		Updaters = nil
+		defer func() { Updaters = updaters }()
+		_, err = defaultPatcher.Apply(&dest, map[string]interface{}{"field5": 1.0})
+		require.NoError(t, err)
+		require.Equal(t, 1, dest.Field5)
+	})
+
+	t.Run("Checked updaters are copied", func(t *testing.T) {
+		updaters := []Updater{UpdaterFunc(UpdateInt)}
+		patcher := NewPatcher(WithCheckedUpdaters(updaters...))
+		updaters[0] = UpdaterFunc(func(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+			fieldValue.SetInt(999)
+			return true, nil
+		})
+
+		dest := destination{}
+		_, err := patcher.Apply(&dest, map[string]interface{}{"field5": 1.0})
+		require.NoError(t, err)
+		require.Equal(t, 1, dest.Field5)
+	})
+
+	t.Run("Skip conditions are evaluated once per field", func(t *testing.T) {
+		var calls int
+		patcher := NewPatcher(WithSkipConditions(func(field reflect.StructField) bool {
+			calls++
+			return SkipReadOnly(field)
+		}))
+		for i := 0; i < 3; i++ {
+			dest := destination{}
+			_, err := patcher.Apply(&dest, map[string]interface{}{"field0": "foo", "field1": "foo"})
+			require.NoError(t, err)
+			require.Equal(t, "", dest.Field0)
+		}
+		require.Equal(t, reflect.TypeOf(destination{}).NumField(), calls)
+	})
+
+	t.Run("JSON Patch", func(t *testing.T) {
+		dest := destination{}
+		updated, err := NewPatcher().JSONPatch(&dest, []Operation{{Op: OpReplace, Path: "/field11/fielda", Value: "foo"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Field11.FieldA"}, updated)
+		require.Equal(t, "foo", dest.Field11.FieldA)
+	})
+
+	t.Run("Concurrent use", func(t *testing.T) {
+		patcher := NewPatcher()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				dest := destination{}
+				_, err := patcher.Apply(&dest, map[string]interface{}{"field1": "foo", "field11p": map[string]interface{}{"fieldb": "bar"}})
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestPatcherStrict(t *testing.T) {
+	patcher := NewPatcher(WithStrict(), WithCollectErrors())
+	dest := destination{}
+	result, err := patcher.Apply(&dest, map[string]interface{}{
+		"field0":  "foo",
+		"field1":  "foo",
+		"unknown": 1,
+		"field11": map[string]interface{}{"fielda": "bar", "fieldc": "baz"},
+	})
+	require.Equal(t, []string{"Field1", "Field11.FieldA"}, result.Updated)
+
+	var fieldErrors FieldErrors
+	require.True(t, errors.As(err, &fieldErrors))
+	require.Len(t, fieldErrors, 3)
+	require.Equal(t, "field0", fieldErrors[0].Path)
+	require.Equal(t, ReasonReadOnly, fieldErrors[0].Reason)
+	require.True(t, errors.Is(fieldErrors[0], ErrReadOnly))
+	require.Equal(t, "unknown", fieldErrors[1].Path)
+	require.Equal(t, ReasonUnknownPath, fieldErrors[1].Reason)
+	require.Equal(t, "field11.fieldc", fieldErrors[2].Path)
+	require.Equal(t, ReasonUnknownPath, fieldErrors[2].Reason)
+
+	_, err = NewPatcher(WithStrict()).Apply(&dest, map[string]interface{}{"unknown": 1})
+	require.True(t, errors.Is(err, ErrPathNotFound))
+}
+
+func TestPatcherStrictPathKeys(t *testing.T) {
+	partial := map[string]interface{}{
+		"field1":         "foo",
+		"/field0":        "foo",
+		"unknown.key":    1,
+		"/unknown":       1,
+		"field11.fieldc": "baz",
+	}
+
+	// path keys naming unknown or read only fields are ignored like plain keys
+	dest := destination{}
+	result, err := NewPatcher().Apply(&dest, partial)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Field1"}, result.Updated)
+	require.Equal(t, destination{Field1: "foo"}, dest)
+
+	// and reported when the keys are strict
+	dest = destination{}
+	result, err = NewPatcher(WithStrict(), WithCollectErrors()).Apply(&dest, partial)
+	require.Equal(t, []string{"Field1"}, result.Updated)
+	var fieldErrors FieldErrors
+	require.True(t, errors.As(err, &fieldErrors))
+	require.Len(t, fieldErrors, 4)
+	require.Equal(t, "/field0", fieldErrors[0].Path)
+	require.Equal(t, ReasonReadOnly, fieldErrors[0].Reason)
+	require.Equal(t, "/unknown", fieldErrors[1].Path)
+	require.Equal(t, ReasonUnknownPath, fieldErrors[1].Reason)
+	require.Equal(t, "field11.fieldc", fieldErrors[2].Path)
+	require.Equal(t, ReasonUnknownPath, fieldErrors[2].Reason)
+	require.Equal(t, "unknown.key", fieldErrors[3].Path)
+	require.Equal(t, ReasonUnknownPath, fieldErrors[3].Reason)
+}
+
+func TestPatcherCaseInsensitiveKeys(t *testing.T) {
+	patcher := NewPatcher(WithCaseInsensitiveKeys(), WithStrict())
+	dest := destination{}
+	result, err := patcher.Apply(&dest, map[string]interface{}{
+		"FIELD1":         "foo",
+		"Field11.FieldA": "bar",
+		"field11p":       map[string]interface{}{"FieldB": "baz"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Field1", "Field11p.FieldB", "Field11.FieldA"}, result.Updated)
+	require.Equal(t, "foo", dest.Field1)
+	require.Equal(t, "bar", dest.Field11.FieldA)
+	require.Equal(t, "baz", dest.Field11p.FieldB)
+
+	// an exact match is preferred
+	result, err = patcher.Apply(&dest, map[string]interface{}{"FIELD1": "foo", "field1": "bar"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Field1"}, result.Updated)
+	require.Equal(t, "bar", dest.Field1)
+}
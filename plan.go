@@ -0,0 +1,100 @@
+package gopartial
+
+import (
+	"reflect"
+	"sync"
+)
+
+// plan is the reflection work on a struct type that is done once
+// and shared by every update of that type.
+//
+// The plan holds no updater: the ordered updaters are chosen by the value as much as by the field,
+// the first one returning true wins, so none can be picked ahead of the value. The updaters of
+// a Registry are the pre-selected ones, looked up by field type in constant time.
+// The package-level functions get their skip conditions on every call and may be given other ones,
+// so only a Patcher, which copies its skip conditions, caches their result in its own plans.
+// BenchmarkPatcher and BenchmarkPatcherRegistry measure both against BenchmarkPartialUpdate.
+type plan struct {
+	fields []fieldPlan
+}
+
+// fieldPlan describes a settable field of a struct type
+type fieldPlan struct {
+	index int
+	field reflect.StructField
+	// key is the partial key of the field for the plan's tag name
+	key string
+	// skipped caches the skip conditions of a Patcher, see config.skipField
+	skipped bool
+	// null is the null policy of the patch tag, 0 when the field has none
+	null NullPolicy
+	// mergeKey is the key matching the elements of a slice of structs, see config.mergeSlice
+	mergeKey string
+}
+
+// typePlans holds the plans of a struct type by tag name
+type typePlans struct {
+	mu    sync.RWMutex
+	byTag map[string]*plan
+}
+
+// planCache caches the plans of every struct type, it is safe for concurrent use
+type planCache struct {
+	// types holds the *typePlans of every struct type by reflect.Type
+	types sync.Map
+}
+
+// plans is the cache of the package-level functions
+var plans = &planCache{}
+
+// planFor returns the plan of struct type t for the tag name, building it on first use
+func (c *config) planFor(t reflect.Type) *plan {
+	cache := c.plans
+	if cache == nil {
+		cache = plans
+	}
+
+	cached, ok := cache.types.Load(t)
+	if !ok {
+		cached, _ = cache.types.LoadOrStore(t, &typePlans{byTag: make(map[string]*plan)})
+	}
+	tp := cached.(*typePlans)
+
+	tp.mu.RLock()
+	p, ok := tp.byTag[c.tagName]
+	tp.mu.RUnlock()
+	if ok {
+		return p
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if p, ok := tp.byTag[c.tagName]; ok {
+		return p
+	}
+	p = c.newPlan(t)
+	tp.byTag[c.tagName] = p
+	return p
+}
+
+// newPlan walks the fields of struct type t
+func (c *config) newPlan(t reflect.Type) *plan {
+	p := &plan{fields: make([]fieldPlan, 0, t.NumField())}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// unexported fields cannot be set
+		if field.PkgPath != "" {
+			continue
+		}
+
+		p.fields = append(p.fields, fieldPlan{
+			index:    i,
+			field:    field,
+			key:      c.key(field),
+			skipped:  c.cachedSkips && c.skip(field),
+			null:     nullPolicyOf(field),
+			mergeKey: patchOption(field.Tag.Get(PatchTag), "mergeKey"),
+		})
+	}
+	return p
+}
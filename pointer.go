@@ -0,0 +1,319 @@
+package gopartial
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("Invalid path %v, must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// isPathKey reports whether a partial key addresses a nested value,
+// either as a JSON Pointer (/items/2/qty) or as dotted field keys (address.city)
+func isPathKey(key string) bool {
+	return strings.HasPrefix(key, "/") || strings.Contains(key, ".")
+}
+
+// pathTokens splits a path key into its reference tokens
+func pathTokens(key string) ([]string, error) {
+	if strings.HasPrefix(key, "/") {
+		return parsePointer(key)
+	}
+	return strings.Split(key, "."), nil
+}
+
+// updatePaths applies the path keys of partial that don't match a field of valueOfDest.
+// Nil pointers along the path are allocated, slices are indexed and maps are looked up by key.
+// Like plain keys, the path keys naming an unknown or read only field are ignored unless
+// the update is strict. name and path are the field name and the key path of valueOfDest,
+// see updateStruct. Returns the full names of the updated values.
+func (c *config) updatePaths(valueOfDest reflect.Value, partial map[string]interface{}, name string, path string) ([]string, error) {
+	keys := make([]string, 0)
+	for key := range partial {
+		if !isPathKey(key) {
+			continue
+		}
+		if _, err := c.fieldByKey(valueOfDest, key); err != nil {
+			keys = append(keys, key)
+		}
+	}
+	// keep the updated names in a stable order
+	sort.Strings(keys)
+
+	fieldsUpdated := make([]string, 0)
+	var fieldErrors FieldErrors
+	for _, key := range keys {
+		tokens, err := pathTokens(key)
+		if err == nil {
+			err = c.checkPath(valueOfDest.Type(), tokens)
+			if err != nil && !c.strict {
+				continue
+			}
+		}
+		if err == nil {
+			var updated []string
+			_, err = c.applyAt(valueOfDest, name, tokens, true, func(container reflect.Value, token string, targetName string) error {
+				var err error
+				updated, err = c.updateChild(container, token, targetName, partial[key], joinPath(path, key), key)
+				return err
+			})
+			fieldsUpdated = append(fieldsUpdated, updated...)
+		}
+		if err == ErrPathNotFound || err == ErrReadOnly {
+			err = newPathError(joinPath(path, key), key, partial[key], err)
+		}
+		if err != nil {
+			if err := c.collect(&fieldErrors, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldsUpdated, fieldErrors
+	}
+	return fieldsUpdated, nil
+}
+
+// updateChild partially updates the value referenced by token inside container with val,
+// adding the key when container is a map. path and key are the ones of the partial value.
+func (c *config) updateChild(container reflect.Value, token string, name string, val interface{}, path string, key string) ([]string, error) {
+	var target reflect.Value
+	if container.Kind() == reflect.Map {
+		if container.Type().Key().Kind() != reflect.String {
+			return nil, ErrPathNotFound
+		}
+		// map elements are not addressable, update a copy and put it back
+		target = reflect.New(container.Type().Elem()).Elem()
+		if existing, err := c.child(container, token); err == nil {
+			target.Set(existing)
+		}
+	} else {
+		child, err := c.child(container, token)
+		if err != nil {
+			return nil, err
+		}
+		target = child
+	}
+
+	updated, updateSuccess, err := c.forChild(container, token, val).updateValue(nil, target, val, name, path)
+	if !updateSuccess {
+		fieldError := newFieldError(path, key, target.Type(), val, err)
+		fieldError.Field = name
+		if container.Kind() == reflect.Struct {
+			fieldError.Struct = container.Type().Name()
+		}
+		return nil, fieldError
+	}
+
+	if updateSuccess && container.Kind() == reflect.Map {
+		if container.IsNil() {
+			container.Set(reflect.MakeMap(container.Type()))
+		}
+		container.SetMapIndex(mapKey(container.Type(), token), target)
+	}
+	return updated, err
+}
+
+// applyPointer parses pointer and calls applyAt with its reference tokens
+func (c *config) applyPointer(value reflect.Value, pointer string, create bool, fn func(container reflect.Value, token string, name string) error) ([]string, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := c.applyAt(value, "", tokens, create, fn)
+	if err == ErrPathNotFound || err == ErrReadOnly {
+		return nil, newPathError(pointer, "", nil, err)
+	}
+	return updated, err
+}
+
+// applyAt walks value down to the container of the last token and calls fn with it,
+// the last token and the name of the target, where name is the name of value itself.
+// Nil pointers along the way are allocated when create is true. Map and interface
+// values are not addressable so they are walked through a copy which is stored back
+// once fn succeeds.
+// Returns the name of the target.
+func (c *config) applyAt(value reflect.Value, name string, tokens []string, create bool, fn func(container reflect.Value, token string, name string) error) ([]string, error) {
+	var targetName string
+	err := c.walk(value, tokens, name, create, func(container reflect.Value, token string, containerName string) error {
+		targetName = c.childName(container, token, containerName)
+		return fn(container, token, targetName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []string{targetName}, nil
+}
+
+// walk implements applyAt
+func (c *config) walk(value reflect.Value, tokens []string, name string, create bool, fn func(container reflect.Value, token string, name string) error) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			if !create {
+				return ErrPathNotFound
+			}
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return c.walk(value.Elem(), tokens, name, create, fn)
+	case reflect.Interface:
+		if value.IsNil() {
+			return ErrPathNotFound
+		}
+		elem := deepCopy(value.Elem())
+		if err := c.walk(elem, tokens, name, create, fn); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	if len(tokens) == 1 {
+		return fn(value, tokens[0], name)
+	}
+
+	child, err := c.child(value, tokens[0])
+	if err != nil {
+		return err
+	}
+	childName := c.childName(value, tokens[0], name)
+
+	if value.Kind() == reflect.Map {
+		// map elements are not addressable, walk a copy and put it back
+		elem := deepCopy(child)
+		if err := c.walk(elem, tokens[1:], childName, create, fn); err != nil {
+			return err
+		}
+		value.SetMapIndex(mapKey(value.Type(), tokens[0]), elem)
+		return nil
+	}
+
+	return c.walk(child, tokens[1:], childName, create, fn)
+}
+
+// child returns the value referenced by token inside container
+func (c *config) child(container reflect.Value, token string) (reflect.Value, error) {
+	switch container.Kind() {
+	case reflect.Struct:
+		field, err := c.fieldByKey(container, token)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return container.Field(field.index), nil
+	case reflect.Slice:
+		if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < container.Len() {
+			return container.Index(i), nil
+		}
+	case reflect.Map:
+		if container.Type().Key().Kind() == reflect.String {
+			if elem := container.MapIndex(mapKey(container.Type(), token)); elem.IsValid() {
+				return elem, nil
+			}
+		}
+	}
+
+	return reflect.Value{}, ErrPathNotFound
+}
+
+// childName returns the name of the value referenced by token inside container,
+// struct field names are joined with dots, slice indexes and map keys are put in brackets
+func (c *config) childName(container reflect.Value, token string, name string) string {
+	switch container.Kind() {
+	case reflect.Struct:
+		if field, err := c.fieldByKey(container, token); err == nil {
+			return joinPath(name, field.field.Name)
+		}
+	case reflect.Slice:
+		if token == "-" {
+			token = strconv.Itoa(container.Len())
+		}
+	}
+	return fmt.Sprintf("%v[%v]", name, token)
+}
+
+// checkPath returns ErrPathNotFound or ErrReadOnly when one of tokens names no settable field
+// of the struct it reaches, judging by the types along the path starting at t.
+// The fields of the structs in interfaces are checked when they are reached.
+func (c *config) checkPath(t reflect.Type, tokens []string) error {
+	for _, token := range tokens {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, err := c.fieldOf(t, token)
+			if err != nil {
+				return err
+			}
+			t = field.field.Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// fieldByKey returns the plan of the exported struct field whose key is token.
+// It returns ErrReadOnly when the field matches a skip condition.
+func (c *config) fieldByKey(container reflect.Value, token string) (*fieldPlan, error) {
+	return c.fieldOf(container.Type(), token)
+}
+
+// fieldOf is fieldByKey for the struct type t
+func (c *config) fieldOf(t reflect.Type, token string) (*fieldPlan, error) {
+	fields := c.planFor(t).fields
+	field := findField(fields, token, false)
+	if field == nil && c.caseInsensitive {
+		field = findField(fields, token, true)
+	}
+	if field == nil {
+		return nil, ErrPathNotFound
+	}
+	if c.skipField(field) {
+		return nil, ErrReadOnly
+	}
+	return field, nil
+}
+
+// findField returns the first field of a plan whose key is token, regardless of case when fold is true
+func findField(fields []fieldPlan, token string, fold bool) *fieldPlan {
+	for i := range fields {
+		if fields[i].key == token || fold && strings.EqualFold(fields[i].key, token) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// mapKey converts a reference token to a key of the map type
+func mapKey(typeOfMap reflect.Type, token string) reflect.Value {
+	return reflect.ValueOf(token).Convert(typeOfMap.Key())
+}
+
+// joinPath appends a struct field name to name
+func joinPath(name string, field string) string {
+	if name == "" {
+		return field
+	}
+	return name + "." + field
+}
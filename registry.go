@@ -0,0 +1,138 @@
+package gopartial
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/guregu/null/zero"
+)
+
+// Registry holds updaters by destination type, looked up in constant time instead of trying
+// every updater in turn. For a field, the updater registered for its exact type comes first,
+// or else the one registered for its kind. When it does not handle the value, the value is assigned
+// as usual: directly when the kinds match, or through the ordered updaters.
+// A Registry must not be modified while it is in use, a Patcher uses its own copy.
+type Registry struct {
+	byType map[reflect.Type]Updater
+	byKind map[reflect.Kind]Updater
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		byType: make(map[reflect.Type]Updater),
+		byKind: make(map[reflect.Kind]Updater),
+	}
+}
+
+// StandardRegistry returns a registry of the built-in updaters of AllUpdaters by type and kind,
+// so that no ordered updaters are needed. The updaters telling why a value is rejected are used.
+func StandardRegistry() *Registry {
+	r := NewRegistry()
+	for _, kind := range []reflect.Kind{reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64} {
+		r.RegisterKindChecked(kind, UpdaterFunc(UpdateInt))
+	}
+	for _, kind := range []reflect.Kind{reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64} {
+		r.RegisterKindChecked(kind, UpdaterFunc(UpdateUint))
+	}
+	r.RegisterKindChecked(reflect.Float32, UpdaterFunc(UpdateFloat))
+	r.RegisterKindChecked(reflect.Float64, UpdaterFunc(UpdateFloat))
+	r.RegisterKindChecked(reflect.Bool, UpdaterFunc(UpdateBool))
+
+	// pointers have no kind of their own, register every pointer type
+	for _, v := range []interface{}{int(0), int8(0), int16(0), int32(0), int64(0)} {
+		r.RegisterChecked(reflect.PtrTo(reflect.TypeOf(v)), UpdaterFunc(UpdateInt))
+	}
+	for _, v := range []interface{}{uint(0), uint8(0), uint16(0), uint32(0), uint64(0)} {
+		r.RegisterChecked(reflect.PtrTo(reflect.TypeOf(v)), UpdaterFunc(UpdateUint))
+	}
+	for _, v := range []interface{}{float32(0), float64(0)} {
+		r.RegisterChecked(reflect.PtrTo(reflect.TypeOf(v)), UpdaterFunc(UpdateFloat))
+	}
+	r.RegisterChecked(reflect.PtrTo(reflect.TypeOf(false)), UpdaterFunc(UpdateBool))
+
+	r.RegisterChecked(reflect.TypeOf(time.Time{}), UpdaterFunc(UpdateTime))
+	r.RegisterChecked(reflect.TypeOf(&time.Time{}), UpdaterFunc(UpdateTime))
+	r.RegisterChecked(reflect.TypeOf(null.String{}), UpdaterFunc(UpdateNullString))
+	r.RegisterChecked(reflect.TypeOf(null.Float{}), UpdaterFunc(UpdateNullFloat))
+	r.RegisterChecked(reflect.TypeOf(null.Int{}), UpdaterFunc(UpdateNullInt))
+	r.RegisterChecked(reflect.TypeOf(null.Bool{}), UpdaterFunc(UpdateNullBool))
+	r.RegisterChecked(reflect.TypeOf(null.Time{}), UpdaterFunc(UpdateNullTime))
+
+	for _, v := range []interface{}{
+		sql.NullString{}, sql.NullInt64{}, sql.NullInt32{}, sql.NullInt16{}, sql.NullByte{},
+		sql.NullFloat64{}, sql.NullBool{}, sql.NullTime{},
+	} {
+		r.RegisterChecked(reflect.TypeOf(v), UpdaterFunc(UpdateSQLNull))
+	}
+	for _, v := range []interface{}{zero.String{}, zero.Int{}, zero.Float{}, zero.Bool{}, zero.Time{}} {
+		r.RegisterChecked(reflect.TypeOf(v), UpdaterFunc(UpdateNullType))
+	}
+	return r
+}
+
+// Register sets the updater of the fields of type t, replacing any updater registered for t
+func (r *Registry) Register(t reflect.Type, updater func(reflect.Value, reflect.Value) bool) {
+	r.byType[t] = BoolFunc(updater)
+}
+
+// RegisterChecked sets the updater telling why a value is rejected of the fields of type t,
+// replacing any updater registered for t
+func (r *Registry) RegisterChecked(t reflect.Type, updater Updater) {
+	r.byType[t] = updater
+}
+
+// RegisterKind sets the updater of the fields of the kind that have no updater registered for their type
+func (r *Registry) RegisterKind(kind reflect.Kind, updater func(reflect.Value, reflect.Value) bool) {
+	r.byKind[kind] = BoolFunc(updater)
+}
+
+// RegisterKindChecked sets the updater telling why a value is rejected of the fields of the kind
+// that have no updater registered for their type
+func (r *Registry) RegisterKindChecked(kind reflect.Kind, updater Updater) {
+	r.byKind[kind] = updater
+}
+
+// Clone returns a copy of the registry that can be modified independently
+func (r *Registry) Clone() *Registry {
+	clone := NewRegistry()
+	if r == nil {
+		return clone
+	}
+	for t, updater := range r.byType {
+		clone.byType[t] = updater
+	}
+	for kind, updater := range r.byKind {
+		clone.byKind[kind] = updater
+	}
+	return clone
+}
+
+// lookup returns the updater of the fields of type t, nil when none is registered
+func (r *Registry) lookup(t reflect.Type) Updater {
+	if r == nil {
+		return nil
+	}
+	if updater, ok := r.byType[t]; ok {
+		return updater
+	}
+	return r.byKind[t.Kind()]
+}
+
+// WithRegistry sets the registry of updaters looked up by field type before the ordered updaters
+func WithRegistry(registry *Registry) Option {
+	return func(c *config) {
+		c.registry = registry
+	}
+}
+
+// WithTypeUpdater registers the updater of the fields of type t on a copy of the registry,
+// e.g. to override TimeUpdater for one Patcher only
+func WithTypeUpdater(t reflect.Type, updater func(reflect.Value, reflect.Value) bool) Option {
+	return func(c *config) {
+		c.registry = c.registry.Clone()
+		c.registry.Register(t, updater)
+	}
+}
@@ -0,0 +1,172 @@
+package gopartial
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardRegistry(t *testing.T) {
+	var i = 1
+	partial := map[string]interface{}{
+		"field2":  "foo",
+		"field3":  1,
+		"field3p": 1.5,
+		"field4":  2,
+		"field5":  1.0,
+		"field5p": &i,
+		"field6":  3.0,
+		"field7":  true,
+		"field7p": false,
+		"field8":  true,
+		"field9":  "2017-11-22T20:30:26.716Z",
+		"field9p": 1511382626.0,
+		"field10": "2017-11-22T20:30:26.716Z",
+		"field12": 4.0,
+		"field13": int64(5),
+		"field15": []interface{}{1.0, 2},
+	}
+
+	want := destination{}
+	wantUpdated, err := PartialUpdate(&want, partial, "json", SkipConditions, AllUpdaters)
+	require.NoError(t, err)
+
+	got := destination{}
+	result, err := Apply(&got, partial, WithRegistry(StandardRegistry()), WithUpdaters())
+	require.NoError(t, err)
+	require.Equal(t, wantUpdated, result.Updated)
+	require.Equal(t, want, got)
+
+	_, err = Apply(&got, map[string]interface{}{"field13": 1000}, WithRegistry(StandardRegistry()), WithUpdaters())
+	require.Error(t, err)
+}
+
+func TestRegistryPrecedence(t *testing.T) {
+	type code string
+	type coded struct {
+		Plain string `json:"plain"`
+		Code  code   `json:"code"`
+		Count int    `json:"count"`
+	}
+	prefix := func(p string) func(reflect.Value, reflect.Value) bool {
+		return func(fieldValue reflect.Value, v reflect.Value) bool {
+			if v.Kind() != reflect.String {
+				return false
+			}
+			fieldValue.SetString(p + v.String())
+			return true
+		}
+	}
+
+	registry := NewRegistry()
+	registry.RegisterKind(reflect.String, prefix("kind:"))
+	registry.Register(reflect.TypeOf(code("")), prefix("type:"))
+	registry.Register(reflect.TypeOf(0), func(reflect.Value, reflect.Value) bool { return false })
+
+	dest := coded{}
+	_, err := Apply(&dest, map[string]interface{}{"plain": "a", "code": "b", "count": 1}, WithRegistry(registry))
+	require.NoError(t, err)
+	// the type comes before the kind, and a registered updater returning false falls back to the usual assignment
+	require.Equal(t, coded{Plain: "kind:a", Code: "type:b", Count: 1}, dest)
+}
+
+func TestRegistryObjects(t *testing.T) {
+	type point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	type shape struct {
+		Center point `json:"center"`
+	}
+
+	// a registered updater receives the objects of its type instead of the nested update
+	registry := NewRegistry()
+	registry.Register(reflect.TypeOf(point{}), func(fieldValue reflect.Value, v reflect.Value) bool {
+		m, ok := v.Interface().(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return false
+		}
+		fieldValue.Set(reflect.ValueOf(point{X: 1, Y: 1}))
+		return true
+	})
+
+	dest := shape{}
+	result, err := Apply(&dest, map[string]interface{}{"center": map[string]interface{}{"x": 5}}, WithRegistry(registry))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Center"}, result.Updated)
+	require.Equal(t, point{X: 1, Y: 1}, dest.Center)
+}
+
+func TestRegistryOverrideForOnePatcher(t *testing.T) {
+	fixed := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	patcher := NewPatcher(WithTypeUpdater(reflect.TypeOf(time.Time{}), func(fieldValue reflect.Value, v reflect.Value) bool {
+		if v.Kind() == reflect.String && strings.EqualFold(v.String(), "new year") {
+			fieldValue.Set(reflect.ValueOf(fixed))
+			return true
+		}
+		return false
+	}))
+
+	dest := destination{}
+	_, err := patcher.Apply(&dest, map[string]interface{}{"field9": "new year"})
+	require.NoError(t, err)
+	require.Equal(t, fixed, dest.Field9)
+
+	// the built-in TimeUpdater is still used when the override returns false
+	_, err = patcher.Apply(&dest, map[string]interface{}{"field9": "2017-11-22T20:30:26.716Z"})
+	require.NoError(t, err)
+	require.Equal(t, 2017, dest.Field9.Year())
+
+	// other patchers are not affected
+	_, err = NewPatcher().Apply(&dest, map[string]interface{}{"field9": "new year"})
+	require.Error(t, err)
+}
+
+func TestRegistryClone(t *testing.T) {
+	type label struct {
+		Text string
+	}
+	type labeled struct {
+		Label label  `json:"label"`
+		Name  string `json:"name"`
+	}
+	setLabel := func(fieldValue reflect.Value, v reflect.Value) bool {
+		if v.Kind() != reflect.String {
+			return false
+		}
+		fieldValue.Set(reflect.ValueOf(label{Text: v.String()}))
+		return true
+	}
+	upper := func(fieldValue reflect.Value, v reflect.Value) bool {
+		if v.Kind() != reflect.String {
+			return false
+		}
+		fieldValue.SetString(strings.ToUpper(v.String()))
+		return true
+	}
+
+	registry := NewRegistry()
+	patcher := NewPatcher(WithRegistry(registry))
+
+	// registering after the Patcher is built does not change it
+	registry.Register(reflect.TypeOf(label{}), setLabel)
+	dest := labeled{}
+	_, err := patcher.Apply(&dest, map[string]interface{}{"label": "a"})
+	require.Error(t, err)
+	_, err = Apply(&dest, map[string]interface{}{"label": "a"}, WithRegistry(registry))
+	require.NoError(t, err)
+	require.Equal(t, labeled{Label: label{Text: "a"}}, dest)
+
+	// the clone keeps the updaters of the registry, and registering on it leaves the registry unchanged
+	clone := registry.Clone()
+	clone.RegisterKind(reflect.String, upper)
+	_, err = Apply(&dest, map[string]interface{}{"label": "b", "name": "b"}, WithRegistry(clone))
+	require.NoError(t, err)
+	require.Equal(t, labeled{Label: label{Text: "b"}, Name: "B"}, dest)
+	_, err = Apply(&dest, map[string]interface{}{"name": "c"}, WithRegistry(registry))
+	require.NoError(t, err)
+	require.Equal(t, labeled{Label: label{Text: "b"}, Name: "c"}, dest)
+}
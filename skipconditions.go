@@ -9,10 +9,17 @@ const readOnlyTag = "readonly"
 
 // SkipReadOnly skips all field that has tag readonly
 func SkipReadOnly(field reflect.StructField) bool {
-	props := strings.Split(field.Tag.Get("props"), ",")
+	props := field.Tag.Get("props")
 
-	for _, v := range props {
-		if v == readOnlyTag {
+	// go through the comma separated props without allocating
+	for props != "" {
+		prop := props
+		if i := strings.IndexByte(props, ','); i >= 0 {
+			prop, props = props[:i], props[i+1:]
+		} else {
+			props = ""
+		}
+		if prop == readOnlyTag {
 			return true
 		}
 	}
@@ -0,0 +1,48 @@
+//go:build go1.22
+
+package gopartial
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLNullGeneric(t *testing.T) {
+	type nullable struct {
+		Count sql.Null[uint16] `json:"count"`
+		Name  sql.Null[string] `json:"name"`
+	}
+
+	got := nullable{}
+	_, err := Apply(&got, map[string]interface{}{"count": 12.0, "name": "foo"}, WithUpdaters(AllUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, nullable{Count: sql.Null[uint16]{V: 12, Valid: true}, Name: sql.Null[string]{V: "foo", Valid: true}}, got)
+
+	_, err = Apply(&got, map[string]interface{}{"count": -1}, WithUpdaters(AllUpdaters...))
+	require.Error(t, err)
+
+	_, err = Apply(&got, map[string]interface{}{"count": nil, "name": nil}, WithUpdaters(AllUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, nullable{}, got)
+}
+
+// value is a generic nullable type wrapping sql.Null[T]
+type value[T any] struct{ sql.Null[T] }
+
+func TestNullTypeGeneric(t *testing.T) {
+	type nullable struct {
+		Count value[int16]  `json:"count"`
+		Name  value[string] `json:"name"`
+	}
+
+	got := nullable{}
+	_, err := Apply(&got, map[string]interface{}{"count": "12", "name": "foo"}, WithUpdaters(AllUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, nullable{Count: value[int16]{sql.Null[int16]{V: 12, Valid: true}}, Name: value[string]{sql.Null[string]{V: "foo", Valid: true}}}, got)
+
+	_, err = Apply(&got, map[string]interface{}{"count": nil, "name": nil}, WithUpdaters(AllUpdaters...))
+	require.NoError(t, err)
+	require.Equal(t, nullable{}, got)
+}
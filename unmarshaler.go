@@ -0,0 +1,88 @@
+package gopartial
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	typeOfJSONUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	typeOfScanner         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// UpdateUnmarshaler updates the fields whose type (pointer or value) implements json.Unmarshaler,
+// encoding.TextUnmarshaler or sql.Scanner through the first of them: v is encoded to JSON,
+// passed as text when it is a string, or scanned as it is. null sets a pointer field to nil
+// and is left to the other updaters for a value field. The field is unchanged when v is rejected.
+func UpdateUnmarshaler(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	typeOfElem := fieldValue.Type()
+	isPointer := typeOfElem.Kind() == reflect.Ptr
+	if isPointer {
+		typeOfElem = typeOfElem.Elem()
+	}
+	if !isUnmarshaler(reflect.PtrTo(typeOfElem)) {
+		return false, nil
+	}
+
+	if !v.IsValid() {
+		if !isPointer {
+			return false, nil
+		}
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, nil
+	}
+
+	// unmarshal into a new value so that the field is unchanged on error
+	elem := reflect.New(typeOfElem)
+	if handled, err := unmarshal(elem.Interface(), v); !handled || err != nil {
+		return handled, err
+	}
+	if isPointer {
+		fieldValue.Set(elem)
+	} else {
+		fieldValue.Set(elem.Elem())
+	}
+	return true, nil
+}
+
+// convertedByUpdaters reports whether the built-in updaters convert the fields of type t
+// better than its unmarshaler: time.Time takes unix times, and the Scan method of
+// the database/sql Null types does not parse strings
+func convertedByUpdaters(t reflect.Type) bool {
+	return t == typeOfTime || t == typeOfTimePointer || isSQLNull(t)
+}
+
+// ownsUnmarshaler reports whether the named type t has an unmarshaler, which converts its values
+// instead of the built-in updaters of its kind
+func ownsUnmarshaler(t reflect.Type) bool {
+	return t.PkgPath() != "" && isUnmarshaler(reflect.PtrTo(t))
+}
+
+// isUnmarshaler reports whether t implements any of the interfaces of UpdateUnmarshaler
+func isUnmarshaler(t reflect.Type) bool {
+	return t.Implements(typeOfJSONUnmarshaler) || t.Implements(typeOfTextUnmarshaler) || t.Implements(typeOfScanner)
+}
+
+// unmarshal feeds v to target through the first interface it implements,
+// handled is false when target only unmarshals text and v is not a string
+func unmarshal(target interface{}, v reflect.Value) (bool, error) {
+	switch target := target.(type) {
+	case json.Unmarshaler:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return true, err
+		}
+		return true, target.UnmarshalJSON(data)
+	case encoding.TextUnmarshaler:
+		if v.Kind() != reflect.String {
+			return false, nil
+		}
+		return true, target.UnmarshalText([]byte(v.String()))
+	case sql.Scanner:
+		return true, target.Scan(v.Interface())
+	}
+	return false, nil
+}
@@ -0,0 +1,180 @@
+package gopartial
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/require"
+)
+
+type upper string
+
+var errEmpty = errors.New("empty")
+
+func (u *upper) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errEmpty
+	}
+	*u = upper(strings.ToUpper(string(text)))
+	return nil
+}
+
+type cents int64
+
+func (c *cents) UnmarshalJSON(data []byte) error {
+	var amount float64
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return err
+	}
+	*c = cents(amount * 100)
+	return nil
+}
+
+type pair struct {
+	First  string
+	Second string
+}
+
+func (p *pair) Scan(src interface{}) error {
+	s, ok := src.(string)
+	if !ok || !strings.Contains(s, ",") {
+		return fmt.Errorf("cannot scan %v", src)
+	}
+	parts := strings.SplitN(s, ",", 2)
+	p.First, p.Second = parts[0], parts[1]
+	return nil
+}
+
+type unmarshaled struct {
+	Code   upper       `json:"code"`
+	CodeP  *upper      `json:"codep"`
+	Price  cents       `json:"price"`
+	PriceP *cents      `json:"pricep"`
+	Pair   pair        `json:"pair"`
+	Name   null.String `json:"name"`
+}
+
+func TestUnmarshalers(t *testing.T) {
+	code := upper("OLD")
+	dest := unmarshaled{CodeP: &code}
+	result, err := Apply(&dest, map[string]interface{}{
+		"code":   "abc",
+		"codep":  nil,
+		"price":  1.5,
+		"pricep": 2,
+		"pair":   "a,b",
+		"name":   "foo",
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Code", "CodeP", "Price", "PriceP", "Pair", "Name"}, result.Updated)
+	require.Equal(t, upper("ABC"), dest.Code)
+	require.Nil(t, dest.CodeP)
+	require.Equal(t, cents(150), dest.Price)
+	require.Equal(t, cents(200), *dest.PriceP)
+	require.Equal(t, pair{"a", "b"}, dest.Pair)
+	require.Equal(t, null.StringFrom("foo"), dest.Name)
+
+	_, err = Apply(&dest, map[string]interface{}{"codep": "x"})
+	require.NoError(t, err)
+	require.Equal(t, upper("X"), *dest.CodeP)
+
+	// rejected values leave the field unchanged and keep the error of the unmarshaler
+	_, err = Apply(&dest, map[string]interface{}{"code": ""})
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.True(t, errors.Is(err, errEmpty))
+	require.Equal(t, "code", fieldError.Path)
+	require.Equal(t, upper("ABC"), dest.Code)
+
+	_, err = Apply(&dest, map[string]interface{}{"pair": 1})
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, ReasonTypeMismatch, fieldError.Reason)
+	require.EqualError(t, fieldError.Err, "cannot scan 1")
+	require.Equal(t, pair{"a", "b"}, dest.Pair)
+
+	// text is only unmarshaled from strings, and null is not allowed for a value field
+	_, err = Apply(&dest, map[string]interface{}{"code": 1})
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, ReasonTypeMismatch, fieldError.Reason)
+	_, err = Apply(&dest, map[string]interface{}{"code": nil})
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, ReasonNullNotAllowed, fieldError.Reason)
+}
+
+type money struct {
+	amount   float64
+	currency string
+}
+
+func (m *money) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Currency == "" {
+		v.Currency = "USD"
+	}
+	*m = money{v.Amount, v.Currency}
+	return nil
+}
+
+type priced struct {
+	Price  money  `json:"price"`
+	PriceP *money `json:"pricep"`
+}
+
+func TestUnmarshalerAfterUpdaters(t *testing.T) {
+	// wholeCents takes the numbers as cents where UnmarshalJSON takes them as an amount
+	wholeCents := func(fieldValue reflect.Value, v reflect.Value) bool {
+		if fieldValue.Type() != reflect.TypeOf(cents(0)) || v.Kind() != reflect.Float64 {
+			return false
+		}
+		fieldValue.SetInt(int64(v.Float()))
+		return true
+	}
+
+	// the updaters of the caller come before the unmarshaler of the field type
+	dest := unmarshaled{}
+	_, err := PartialUpdate(&dest, map[string]interface{}{"price": 1250.0, "pricep": 1250.0}, "json", SkipConditions, append([]func(reflect.Value, reflect.Value) bool{wholeCents}, Updaters...))
+	require.NoError(t, err)
+	require.Equal(t, cents(1250), dest.Price)
+	require.Equal(t, cents(1250), *dest.PriceP)
+
+	dest = unmarshaled{}
+	_, err = Apply(&dest, map[string]interface{}{"price": 1250.0}, WithCheckedUpdaters(BoolFunc(wholeCents)))
+	require.NoError(t, err)
+	require.Equal(t, cents(1250), dest.Price)
+
+	// the unmarshaler still converts the values they do not handle, the built-in updaters leave them to it
+	_, err = PartialUpdate(&dest, map[string]interface{}{"price": 12}, "json", SkipConditions, append([]func(reflect.Value, reflect.Value) bool{wholeCents}, Updaters...))
+	require.NoError(t, err)
+	require.Equal(t, cents(1200), dest.Price)
+}
+
+func TestUnmarshalerObjects(t *testing.T) {
+	// objects are unmarshaled instead of applied to the fields of the struct
+	dest := priced{}
+	result, err := Apply(&dest, map[string]interface{}{
+		"price":  map[string]interface{}{"amount": 1.5},
+		"pricep": map[string]interface{}{"amount": 2, "currency": "EUR"},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"Price", "PriceP"}, result.Updated)
+	require.Equal(t, money{1.5, "USD"}, dest.Price)
+	require.Equal(t, &money{2, "EUR"}, dest.PriceP)
+
+	// rejected objects are reported and leave the field unchanged
+	_, err = Apply(&dest, map[string]interface{}{"pricep": map[string]interface{}{"amount": "x"}})
+	var fieldError *FieldError
+	require.True(t, errors.As(err, &fieldError))
+	require.Equal(t, "pricep", fieldError.Path)
+	require.Equal(t, &money{2, "EUR"}, dest.PriceP)
+}
@@ -3,6 +3,8 @@ package gopartial
 import (
 	"database/sql"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/guregu/null"
@@ -10,128 +12,269 @@ import (
 
 // NullStringUpdater update null.String
 func NullStringUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateNullString(fieldValue, v))
+}
+
+// UpdateNullString is the Updater of NullStringUpdater: null or a string is converted to a null.String,
+// other values are not handled
+func UpdateNullString(fieldValue reflect.Value, v reflect.Value) (bool, error) {
 	switch fieldValue.Interface().(type) {
 	case null.String:
 		// if its null value
 		if !v.IsValid() {
 			newValue := reflect.ValueOf(null.String{NullString: sql.NullString{Valid: false}})
 			fieldValue.Set(newValue)
-			return true
+			return true, nil
 		}
 		// only set if underlying type is string
 		if v.Kind() == reflect.String {
 			newValue := reflect.ValueOf(null.String{NullString: sql.NullString{Valid: true, String: v.String()}})
 			fieldValue.Set(newValue)
-			return true
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
 }
 
 // NullFloatUpdater update null.Float64
 func NullFloatUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateNullFloat(fieldValue, v))
+}
+
+// UpdateNullFloat is the Updater of NullFloatUpdater: null or any number is converted to a null.Float,
+// other values are not handled
+func UpdateNullFloat(fieldValue reflect.Value, v reflect.Value) (bool, error) {
 	switch fieldValue.Interface().(type) {
 	case null.Float:
-		// if its null value
-		if !v.IsValid() {
-			newValue := reflect.ValueOf(null.Float{NullFloat64: sql.NullFloat64{Valid: false}})
-			fieldValue.Set(newValue)
-			return true
+		i, u, f, kind := numberOf(v)
+		switch kind {
+		case nullNumber:
+			fieldValue.Set(reflect.ValueOf(null.Float{NullFloat64: sql.NullFloat64{Valid: false}}))
+			return true, nil
+		case notNumber:
+			return false, nil
 		}
-		// only set if underlying type is any int/float
-		if v.Kind() == reflect.Int ||
-			v.Kind() == reflect.Int8 ||
-			v.Kind() == reflect.Int16 ||
-			v.Kind() == reflect.Int32 ||
-			v.Kind() == reflect.Int64 {
-			newValue := reflect.ValueOf(null.Float{NullFloat64: sql.NullFloat64{Valid: true, Float64: float64(v.Int())}})
-			fieldValue.Set(newValue)
-			return true
-		} else if v.Kind() == reflect.Float32 ||
-			v.Kind() == reflect.Float64 {
-			newValue := reflect.ValueOf(null.Float{NullFloat64: sql.NullFloat64{Valid: true, Float64: v.Float()}})
-			fieldValue.Set(newValue)
-			return true
+		x, reason := toFloat(i, u, f, kind, 64)
+		if reason != "" {
+			return true, reason
 		}
+		fieldValue.Set(reflect.ValueOf(null.Float{NullFloat64: sql.NullFloat64{Valid: true, Float64: x}}))
+		return true, nil
 	}
 
-	return false
+	return false, nil
 }
 
 // NullIntUpdater update null.Int
 func NullIntUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateNullInt(fieldValue, v))
+}
+
+// UpdateNullInt is the Updater of NullIntUpdater: null or any number is converted to a null.Int like UpdateInt,
+// a number that does not fit is rejected with ReasonOverflow and other values are not handled
+func UpdateNullInt(fieldValue reflect.Value, v reflect.Value) (bool, error) {
 	switch fieldValue.Interface().(type) {
 	case null.Int:
-		// if its null value
-		if !v.IsValid() {
-			newValue := reflect.ValueOf(null.Int{NullInt64: sql.NullInt64{Valid: false}})
-			fieldValue.Set(newValue)
-			return true
+		i, u, f, kind := numberOf(v)
+		switch kind {
+		case nullNumber:
+			fieldValue.Set(reflect.ValueOf(null.Int{NullInt64: sql.NullInt64{Valid: false}}))
+			return true, nil
+		case notNumber:
+			return false, nil
 		}
-		// only set if underlying type is any int/float
-		if v.Kind() == reflect.Int ||
-			v.Kind() == reflect.Int8 ||
-			v.Kind() == reflect.Int16 ||
-			v.Kind() == reflect.Int32 ||
-			v.Kind() == reflect.Int64 {
-			newValue := reflect.ValueOf(null.Int{NullInt64: sql.NullInt64{Valid: true, Int64: v.Int()}})
-			fieldValue.Set(newValue)
-			return true
-		} else if v.Kind() == reflect.Float32 ||
-			v.Kind() == reflect.Float64 {
-			newValue := reflect.ValueOf(null.Int{NullInt64: sql.NullInt64{Valid: true, Int64: int64(v.Float())}})
-			fieldValue.Set(newValue)
-			return true
+		x, reason := toInt(i, u, f, kind, 64)
+		if reason != "" {
+			return true, reason
 		}
+		fieldValue.Set(reflect.ValueOf(null.Int{NullInt64: sql.NullInt64{Valid: true, Int64: x}}))
+		return true, nil
 	}
 
-	return false
+	return false, nil
 }
 
 // NullBoolUpdater update null.Bool
 func NullBoolUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateNullBool(fieldValue, v))
+}
+
+// UpdateNullBool is the Updater of NullBoolUpdater: null or a bool is converted to a null.Bool,
+// other values are not handled
+func UpdateNullBool(fieldValue reflect.Value, v reflect.Value) (bool, error) {
 	switch fieldValue.Interface().(type) {
 	case null.Bool:
 		// if its null value
 		if !v.IsValid() {
 			newValue := reflect.ValueOf(null.Bool{NullBool: sql.NullBool{Valid: false}})
 			fieldValue.Set(newValue)
-			return true
+			return true, nil
 		}
 		// only set if underlying type is bool
 		if v.Kind() == reflect.Bool {
 			newValue := reflect.ValueOf(null.Bool{NullBool: sql.NullBool{Valid: true, Bool: v.Bool()}})
 			fieldValue.Set(newValue)
-			return true
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
 }
 
 // NullTimeUpdater update null.Time
 func NullTimeUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateNullTime(fieldValue, v))
+}
+
+// UpdateNullTime is the Updater of NullTimeUpdater: null or a RFC 3339 string is converted
+// to a null.Time, a string that is not a valid time is rejected with ReasonUnparseableTime
+func UpdateNullTime(fieldValue reflect.Value, v reflect.Value) (bool, error) {
 	switch fieldValue.Interface().(type) {
 	case null.Time:
 		// if its null value
 		if !v.IsValid() {
 			newValue := reflect.ValueOf(null.Time{})
 			fieldValue.Set(newValue)
-			return true
+			return true, nil
 		}
 		// only set if underlying type is string
 		if v.Kind() == reflect.String {
 			nullTime := null.Time{}
-			if err := nullTime.UnmarshalJSON([]byte(`"` + v.String() + `"`)); err == nil {
-				newValue := reflect.ValueOf(nullTime)
-				fieldValue.Set(newValue)
-				return true
+			if err := nullTime.UnmarshalJSON([]byte(`"` + v.String() + `"`)); err != nil {
+				return true, ReasonUnparseableTime
 			}
+			fieldValue.Set(reflect.ValueOf(nullTime))
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
+}
+
+// SQLNullUpdater update the database/sql Null types (sql.NullString, sql.NullInt64, sql.NullTime... and sql.Null[T])
+func SQLNullUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateSQLNull(fieldValue, v))
+}
+
+// UpdateSQLNull is the Updater of SQLNullUpdater: null sets the field to its invalid zero value,
+// other values are converted to the type of the value like IntUpdater, FloatUpdater, TimeUpdater...
+// and rejected with ReasonOverflow or ReasonUnparseableTime the same way
+func UpdateSQLNull(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	if !isSQLNull(fieldValue.Type()) {
+		return false, nil
+	}
+	// if its null value
+	if !v.IsValid() {
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, nil
+	}
+
+	// convert into a new value so that the field is unchanged on error
+	newValue := reflect.New(fieldValue.Type()).Elem()
+	if handled, err := updateScalar(newValue.Field(0), v); !handled || err != nil {
+		return handled, err
+	}
+	newValue.Field(1).SetBool(true)
+	fieldValue.Set(newValue)
+	return true, nil
+}
+
+// isSQLNull reports whether t is one of the database/sql Null types, a struct of the value and Valid
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null") &&
+		t.NumField() == 2 && t.Field(1).Name == "Valid"
+}
+
+// NullTypeUpdater update the types wrapping a database/sql Null type, like the null and zero packages
+// of github.com/guregu/null v4 and v5 (null.Int32, null.Byte, null.Value[T], zero.Int, zero.Time...)
+func NullTypeUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateNullType(fieldValue, v))
+}
+
+// UpdateNullType is the Updater of NullTypeUpdater: the wrapped Null type is updated like UpdateSQLNull,
+// and numbers can also be given as numeric strings, the empty string being null.
+// The types of a zero package are set to their zero value by null, and are not valid when zero.
+func UpdateNullType(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	if !isNullWrapper(fieldValue.Type()) {
+		return false, nil
+	}
+
+	sqlNull := reflect.New(fieldValue.Type().Field(0).Type).Elem()
+	if v.Kind() == reflect.String && isNumberKind(sqlNull.Field(0).Kind()) {
+		if v.String() == "" {
+			v = reflect.Value{}
+		} else if n, ok := parseNumber(v.String()); ok {
+			v = n
+		} else {
+			return false, nil
+		}
+	}
+	if handled, err := UpdateSQLNull(sqlNull, v); !handled || err != nil {
+		return handled, err
+	}
+	if isZeroPackage(fieldValue.Type()) && sqlNull.Field(0).IsZero() {
+		sqlNull.Field(1).SetBool(false)
+	}
+
+	newValue := reflect.New(fieldValue.Type()).Elem()
+	newValue.Field(0).Set(sqlNull)
+	fieldValue.Set(newValue)
+	return true, nil
+}
+
+// isNullWrapper reports whether t is a struct embedding a database/sql Null type and nothing else
+func isNullWrapper(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 1 && t.Field(0).Anonymous && isSQLNull(t.Field(0).Type)
+}
+
+// isZeroPackage reports whether t belongs to a zero package of github.com/guregu/null,
+// where the zero value is null
+func isZeroPackage(t reflect.Type) bool {
+	return strings.HasPrefix(t.PkgPath(), "github.com/guregu/null") && strings.HasSuffix(t.PkgPath(), "/zero")
+}
+
+// parseNumber returns the int64, uint64 or float64 of a numeric string
+func parseNumber(s string) (reflect.Value, bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return reflect.ValueOf(i), true
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return reflect.ValueOf(u), true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return reflect.ValueOf(f), true
+	}
+	return reflect.Value{}, false
+}
+
+// scalarUpdaters convert the value of a database/sql Null type
+var scalarUpdaters = []func(reflect.Value, reflect.Value) (bool, error){
+	UpdateInt,
+	UpdateUint,
+	UpdateFloat,
+	UpdateTime,
+	UpdateBool,
+	UpdateUnmarshaler,
+}
+
+// updateScalar sets v to fieldValue when it is assignable, or a string to a string field,
+// or else through the first of scalarUpdaters handling v
+func updateScalar(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	if v.Type().AssignableTo(fieldValue.Type()) {
+		fieldValue.Set(v)
+		return true, nil
+	}
+	if fieldValue.Kind() == reflect.String && v.Kind() == reflect.String {
+		fieldValue.SetString(v.String())
+		return true, nil
+	}
+	for _, updater := range scalarUpdaters {
+		if handled, err := updater(fieldValue, v); handled {
+			return true, err
+		}
+	}
+	return false, nil
 }
 
 // MapStringInterfaceUpdater update map[string]interface{}
@@ -145,52 +288,48 @@ func MapStringInterfaceUpdater(fieldValue reflect.Value, v reflect.Value) bool {
 
 // BoolUpdater update bool (pointer or value)
 func BoolUpdater(fieldValue reflect.Value, v reflect.Value) bool {
-	if fieldValue.Kind() == reflect.Bool {
+	return succeeded(UpdateBool(fieldValue, v))
+}
+
+var typeOfBoolPointer = reflect.TypeOf((*bool)(nil))
+
+// UpdateBool is the Updater of BoolUpdater: a bool is set to a bool (pointer or value),
+// null sets a pointer to nil and other values are not handled
+func UpdateBool(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	if fieldValue.Kind() == reflect.Bool && !ownsUnmarshaler(fieldValue.Type()) {
 		if v.Kind() == reflect.Bool {
-			fieldValue.Set(v)
-			return true
+			fieldValue.SetBool(v.Bool())
+			return true, nil
 		}
 	} else if fieldValue.Kind() == reflect.Ptr {
-		// only process if field is pointer to any bool
-		if fieldValue.Type().String() == "*bool" {
+		// only process if field is pointer to bool
+		if fieldValue.Type() == typeOfBoolPointer {
 			if !v.IsValid() {
 				var newBoolValue *bool
 				newValue := reflect.ValueOf(newBoolValue)
 				fieldValue.Set(newValue)
-				return true
+				return true, nil
 			} else if v.Kind() == reflect.Bool {
 				newBoolValue := v.Bool()
 				newValue := reflect.ValueOf(&newBoolValue)
 				fieldValue.Set(newValue)
-				return true
+				return true, nil
 			}
 		}
 	}
 
-	return false
+	return false, nil
 }
 
+// SliceUpdater updates slice fields from slices and arrays whose elements are converted one by one
+// with the package-level Updaters. The field is unchanged when an element cannot be converted.
+// The updates convert the elements of slice and map fields with their own updaters instead.
 func SliceUpdater(fieldValue reflect.Value, v reflect.Value) bool {
-	if fieldValue.Kind() == reflect.Slice {
+	if fieldValue.Kind() == reflect.Slice && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) {
 		nval := reflect.MakeSlice(fieldValue.Type(), v.Len(), v.Cap())
 		for i := 0; i < v.Len(); i++ {
-			el := v.Index(i)
-			if el.Kind() == reflect.Ptr || el.Kind() == reflect.Interface {
-				el = el.Elem()
-			}
-
-			if nval.Index(i).Kind() == el.Kind() {
-				nval.Index(i).Set(el)
-			} else {
-				// go through all extended process types
-				var updateSuccess bool
-				for _, updater := range Updaters {
-					updateSuccess = updater(nval.Index(i), el)
-					if updateSuccess {
-						// the first updateSuccess found, break the loop
-						break
-					}
-				}
+			if !updateElement(nval.Index(i), v.Index(i)) {
+				return false
 			}
 		}
 
@@ -201,639 +340,174 @@ func SliceUpdater(fieldValue reflect.Value, v reflect.Value) bool {
 	return false
 }
 
-// IntUpdater update int (any int type Int8, Int16, Int32, Int64 and whether its a pointer or a value)
-func IntUpdater(fieldValue reflect.Value, v reflect.Value) bool {
-	switch fieldValue.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+// updateElement converts el to the slice element elem
+func updateElement(elem reflect.Value, el reflect.Value) bool {
+	if el.Kind() == reflect.Ptr || el.Kind() == reflect.Interface {
+		el = el.Elem()
+	}
 
-		switch v.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if fieldValue.OverflowInt(v.Int()) {
-				return false
-			}
-			fieldValue.SetInt(v.Int())
-			return true
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			if fieldValue.OverflowInt(int64(v.Uint())) {
-				return false
-			}
-			fieldValue.SetInt(int64(v.Uint()))
-			return true
-		case reflect.Float32, reflect.Float64:
-			if fieldValue.OverflowInt(int64(v.Float())) {
-				return false
-			}
-			fieldValue.SetInt(int64(v.Float()))
+	// null leaves the elements that can be nil to nil
+	if !el.IsValid() && canBeNil(elem.Kind()) {
+		return true
+	}
+	if elem.Kind() == el.Kind() && setConverted(elem, el) {
+		return true
+	}
+	// go through all extended process types
+	for _, updater := range Updaters {
+		if updater(elem, el) {
+			// the first updateSuccess found, break the loop
 			return true
 		}
-
-	case reflect.Ptr:
-
-		if !v.IsValid() {
-			if fieldValue.Type().String() == "*int" {
-				var newNullInt *int
-				newValue := reflect.ValueOf(newNullInt)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*int8" {
-				var newNullInt8 *int8
-				newValue := reflect.ValueOf(newNullInt8)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*int16" {
-				var newNullInt16 *int16
-				newValue := reflect.ValueOf(newNullInt16)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*int32" {
-				var newNullInt32 *int32
-				newValue := reflect.ValueOf(newNullInt32)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*int64" {
-				var newNullInt64 *int64
-				newValue := reflect.ValueOf(newNullInt64)
-				fieldValue.Set(newValue)
-				return true
-			}
-		} else {
-
-			switch v.Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				vv := v.Int()
-
-				if fieldValue.Type().String() == "*int" {
-					var newIntValue int
-					if isOverflowInt(newIntValue, vv) {
-						return false
-					}
-					newIntValue = int(vv)
-					newValue := reflect.ValueOf(&newIntValue)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int8" {
-					var newInt8Value int8
-					if isOverflowInt(newInt8Value, vv) {
-						return false
-					}
-					newInt8Value = int8(vv)
-					newValue := reflect.ValueOf(&newInt8Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int16" {
-					var newInt16Value int16
-					if isOverflowInt(newInt16Value, vv) {
-						return false
-					}
-					newInt16Value = int16(vv)
-					newValue := reflect.ValueOf(&newInt16Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int32" {
-					var newInt32Value int32
-					if isOverflowInt(newInt32Value, vv) {
-						return false
-					}
-					newInt32Value = int32(vv)
-					newValue := reflect.ValueOf(&newInt32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int64" {
-					var newInt64Value int64
-					if isOverflowInt(newInt64Value, vv) {
-						return false
-					}
-					newInt64Value = vv
-					newValue := reflect.ValueOf(&newInt64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				vv := v.Uint()
-
-				if fieldValue.Type().String() == "*int" {
-					var newIntValue int
-					if isOverflowInt(newIntValue, int64(vv)) {
-						return false
-					}
-					newIntValue = int(vv)
-					newValue := reflect.ValueOf(&newIntValue)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int8" {
-					var newInt8Value int8
-					if isOverflowInt(newInt8Value, int64(vv)) {
-						return false
-					}
-					newInt8Value = int8(vv)
-					newValue := reflect.ValueOf(&newInt8Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int16" {
-					var newInt16Value int16
-					if isOverflowInt(newInt16Value, int64(vv)) {
-						return false
-					}
-					newInt16Value = int16(vv)
-					newValue := reflect.ValueOf(&newInt16Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int32" {
-					var newInt32Value int32
-					if isOverflowInt(newInt32Value, int64(vv)) {
-						return false
-					}
-					newInt32Value = int32(vv)
-					newValue := reflect.ValueOf(&newInt32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int64" {
-					var newInt64Value int64
-					if isOverflowInt(newInt64Value, int64(vv)) {
-						return false
-					}
-					newInt64Value = int64(vv)
-					newValue := reflect.ValueOf(&newInt64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			case reflect.Float32, reflect.Float64:
-				vv := v.Float()
-
-				if fieldValue.Type().String() == "*int" {
-					var newIntValue int
-					if isOverflowInt(newIntValue, int64(vv)) {
-						return false
-					}
-					newIntValue = int(vv)
-					newValue := reflect.ValueOf(&newIntValue)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int8" {
-					var newInt8Value int8
-					if isOverflowInt(newInt8Value, int64(vv)) {
-						return false
-					}
-					newInt8Value = int8(vv)
-					newValue := reflect.ValueOf(&newInt8Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int16" {
-					var newInt16Value int16
-					if isOverflowInt(newInt16Value, int64(vv)) {
-						return false
-					}
-					newInt16Value = int16(vv)
-					newValue := reflect.ValueOf(&newInt16Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int32" {
-					var newInt32Value int32
-					if isOverflowInt(newInt32Value, int64(vv)) {
-						return false
-					}
-					newInt32Value = int32(vv)
-					newValue := reflect.ValueOf(&newInt32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*int64" {
-					var newInt64Value int64
-					if isOverflowInt(newInt64Value, int64(vv)) {
-						return false
-					}
-					newInt64Value = int64(vv)
-					newValue := reflect.ValueOf(&newInt64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			}
-
-		}
-
 	}
-
 	return false
 }
 
+// IntUpdater update int (any int type Int8, Int16, Int32, Int64 and whether its a pointer or a value)
+func IntUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateInt(fieldValue, v))
+}
+
 // UintUpdater update int (any int type Uint8, Uint16, Uint32, Uint64 and whether its a pointer or a value)
 func UintUpdater(fieldValue reflect.Value, v reflect.Value) bool {
-	// check for underflow first
-	switch v.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if v.Int() < 0 {
-			return false
-		}
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if v.Uint() < 0 {
-			return false
-		}
-	case reflect.Float32, reflect.Float64:
-		if v.Float() < 0 {
-			return false
-		}
-	}
-
-	switch fieldValue.Kind() {
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		switch v.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if fieldValue.OverflowUint(uint64(v.Int())) {
-				return false
-			}
-			fieldValue.SetUint(uint64(v.Int()))
-			return true
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			if fieldValue.OverflowUint(v.Uint()) {
-				return false
-			}
-			fieldValue.SetUint(v.Uint())
-			return true
-		case reflect.Float32, reflect.Float64:
-			if fieldValue.OverflowUint(uint64(v.Float())) {
-				return false
-			}
-			fieldValue.SetUint(uint64(v.Float()))
-			return true
-		}
-
-	case reflect.Ptr:
+	return succeeded(UpdateUint(fieldValue, v))
+}
 
-		if !v.IsValid() {
-			if fieldValue.Type().String() == "*uint" {
-				var newNullUint *uint
-				newValue := reflect.ValueOf(newNullUint)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*uint8" {
-				var newNullUint8 *uint8
-				newValue := reflect.ValueOf(newNullUint8)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*uint16" {
-				var newNullUint16 *uint16
-				newValue := reflect.ValueOf(newNullUint16)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*uint32" {
-				var newNullUint32 *uint32
-				newValue := reflect.ValueOf(newNullUint32)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*uint64" {
-				var newNullUint64 *uint64
-				newValue := reflect.ValueOf(newNullUint64)
-				fieldValue.Set(newValue)
-				return true
-			}
-		} else {
+// FloatUpdater update float (Float32, Float64 and whether its a pointer or a value)
+func FloatUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateFloat(fieldValue, v))
+}
 
-			switch v.Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				vv := v.Int()
-
-				if fieldValue.Type().String() == "*uint" {
-					var newUintValue uint
-					if isOverflowUint(newUintValue, uint64(vv)) {
-						return false
-					}
-					newUintValue = uint(vv)
-					newValue := reflect.ValueOf(&newUintValue)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint8" {
-					var newUint8Value uint8
-					if isOverflowUint(newUint8Value, uint64(vv)) {
-						return false
-					}
-					newUint8Value = uint8(vv)
-					newValue := reflect.ValueOf(&newUint8Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint16" {
-					var newUint16Value uint16
-					if isOverflowUint(newUint16Value, uint64(vv)) {
-						return false
-					}
-					newUint16Value = uint16(vv)
-					newValue := reflect.ValueOf(&newUint16Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint32" {
-					var newUint32Value uint32
-					if isOverflowUint(newUint32Value, uint64(vv)) {
-						return false
-					}
-					newUint32Value = uint32(vv)
-					newValue := reflect.ValueOf(&newUint32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint64" {
-					var newUint64Value uint64
-					if isOverflowUint(newUint64Value, uint64(vv)) {
-						return false
-					}
-					newUint64Value = uint64(vv)
-					newValue := reflect.ValueOf(&newUint64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				vv := v.Uint()
-
-				if fieldValue.Type().String() == "*uint" {
-					var newUintValue uint
-					if isOverflowUint(newUintValue, vv) {
-						return false
-					}
-					newUintValue = uint(vv)
-					newValue := reflect.ValueOf(&newUintValue)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint8" {
-					var newUint8Value uint8
-					if isOverflowUint(newUint8Value, vv) {
-						return false
-					}
-					newUint8Value = uint8(vv)
-					newValue := reflect.ValueOf(&newUint8Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint16" {
-					var newUint16Value uint16
-					if isOverflowUint(newUint16Value, vv) {
-						return false
-					}
-					newUint16Value = uint16(vv)
-					newValue := reflect.ValueOf(&newUint16Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint32" {
-					var newUint32Value uint32
-					if isOverflowUint(newUint32Value, vv) {
-						return false
-					}
-					newUint32Value = uint32(vv)
-					newValue := reflect.ValueOf(&newUint32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint64" {
-					var newUint64Value uint64
-					if isOverflowUint(newUint64Value, vv) {
-						return false
-					}
-					newUint64Value = vv
-					newValue := reflect.ValueOf(&newUint64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			case reflect.Float32, reflect.Float64:
-				vv := v.Float()
-
-				if fieldValue.Type().String() == "*uint" {
-					var newUintValue uint
-					if isOverflowUint(newUintValue, uint64(vv)) {
-						return false
-					}
-					newUintValue = uint(vv)
-					newValue := reflect.ValueOf(&newUintValue)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint8" {
-					var newUint8Value uint8
-					if isOverflowUint(newUint8Value, uint64(vv)) {
-						return false
-					}
-					newUint8Value = uint8(vv)
-					newValue := reflect.ValueOf(&newUint8Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint16" {
-					var newUint16Value uint16
-					if isOverflowUint(newUint16Value, uint64(vv)) {
-						return false
-					}
-					newUint16Value = uint16(vv)
-					newValue := reflect.ValueOf(&newUint16Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint32" {
-					var newUint32Value uint32
-					if isOverflowUint(newUint32Value, uint64(vv)) {
-						return false
-					}
-					newUint32Value = uint32(vv)
-					newValue := reflect.ValueOf(&newUint32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*uint64" {
-					var newUint64Value uint64
-					if isOverflowUint(newUint64Value, uint64(vv)) {
-						return false
-					}
-					newUint64Value = uint64(vv)
-					newValue := reflect.ValueOf(&newUint64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			}
+// TimeUpdater update time.Time (pointer or value)
+func TimeUpdater(fieldValue reflect.Value, v reflect.Value) bool {
+	return succeeded(UpdateTime(fieldValue, v))
+}
 
+// UpdateInt is the Updater of IntUpdater: any number is converted to any int type
+// (pointer or value), a number that does not fit is rejected with ReasonOverflow
+func UpdateInt(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	return updateNumber(fieldValue, v, func(elem reflect.Value, i int64, u uint64, f float64, kind numberKind) Reason {
+		x, reason := toInt(i, u, f, kind, elem.Type().Bits())
+		if reason == "" {
+			elem.SetInt(x)
 		}
-
-	}
-
-	return false
+		return reason
+	}, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64)
 }
 
-// FloatUpdater update int (any float type Float8, Float16, Float32, Float64 and whether its a pointer or a value)
-func FloatUpdater(fieldValue reflect.Value, v reflect.Value) bool {
-	switch fieldValue.Kind() {
-	case reflect.Float32, reflect.Float64:
-
-		switch v.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if fieldValue.OverflowFloat(float64(v.Int())) {
-				return false
-			}
-			fieldValue.SetFloat(float64(v.Int()))
-			return true
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			if fieldValue.OverflowFloat(float64(v.Uint())) {
-				return false
-			}
-			fieldValue.SetFloat(float64(v.Uint()))
-			return true
-		case reflect.Float32, reflect.Float64:
-			if fieldValue.OverflowFloat(v.Float()) {
-				return false
-			}
-			fieldValue.SetFloat(v.Float())
-			return true
+// UpdateUint is the Updater of UintUpdater: any number is converted to any uint type
+// (pointer or value), a negative number or one that does not fit is rejected with ReasonOverflow
+func UpdateUint(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	return updateNumber(fieldValue, v, func(elem reflect.Value, i int64, u uint64, f float64, kind numberKind) Reason {
+		x, reason := toUint(i, u, f, kind, elem.Type().Bits())
+		if reason == "" {
+			elem.SetUint(x)
 		}
+		return reason
+	}, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64)
+}
 
-	case reflect.Ptr:
-
-		// only process if field is pointer to any float
-		if fieldValue.Type().String() != "*float32" && fieldValue.Type().String() != "*float64" {
-			return false
+// UpdateFloat is the Updater of FloatUpdater: any number is converted to any float type
+// (pointer or value), a number that does not fit is rejected with ReasonOverflow
+func UpdateFloat(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	return updateNumber(fieldValue, v, func(elem reflect.Value, i int64, u uint64, f float64, kind numberKind) Reason {
+		x, reason := toFloat(i, u, f, kind, elem.Type().Bits())
+		if reason == "" {
+			elem.SetFloat(x)
 		}
+		return reason
+	}, reflect.Float32, reflect.Float64)
+}
 
-		if !v.IsValid() {
-			if fieldValue.Type().String() == "*float32" {
-				var newFloat32Value *float32
-				newValue := reflect.ValueOf(newFloat32Value)
-				fieldValue.Set(newValue)
-				return true
-			} else if fieldValue.Type().String() == "*float64" {
-				var newFloat64Value *float64
-				newValue := reflect.ValueOf(newFloat64Value)
-				fieldValue.Set(newValue)
-				return true
-			}
-		} else {
-
-			switch v.Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				vv := v.Int()
-
-				if fieldValue.Type().String() == "*float32" {
-					var newFloat32Value float32
-					if isOverflowFloat(newFloat32Value, float64(vv)) {
-						return false
-					}
-					newFloat32Value = float32(vv)
-					newValue := reflect.ValueOf(&newFloat32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*float64" {
-					var newFloat64Value float64
-					if isOverflowFloat(newFloat64Value, float64(vv)) {
-						return false
-					}
-					newFloat64Value = float64(vv)
-					newValue := reflect.ValueOf(&newFloat64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				vv := v.Uint()
-
-				if fieldValue.Type().String() == "*float32" {
-					var newFloat32Value float32
-					if isOverflowFloat(newFloat32Value, float64(vv)) {
-						return false
-					}
-					newFloat32Value = float32(vv)
-					newValue := reflect.ValueOf(&newFloat32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*float64" {
-					var newFloat64Value float64
-					if isOverflowFloat(newFloat64Value, float64(vv)) {
-						return false
-					}
-					newFloat64Value = float64(vv)
-					newValue := reflect.ValueOf(&newFloat64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			case reflect.Float32, reflect.Float64:
-				vv := v.Float()
-
-				if fieldValue.Type().String() == "*float32" {
-					var newFloat32Value float32
-					if isOverflowFloat(newFloat32Value, vv) {
-						return false
-					}
-					newFloat32Value = float32(vv)
-					newValue := reflect.ValueOf(&newFloat32Value)
-					fieldValue.Set(newValue)
-					return true
-				} else if fieldValue.Type().String() == "*float64" {
-					var newFloat64Value float64
-					if isOverflowFloat(newFloat64Value, vv) {
-						return false
-					}
-					newFloat64Value = vv
-					newValue := reflect.ValueOf(&newFloat64Value)
-					fieldValue.Set(newValue)
-					return true
-				}
-			}
-
-		}
+// updateNumber sets a number field of one of kinds, or a pointer to it, through set.
+// A null value sets a pointer to nil and values that are not numbers are not handled.
+func updateNumber(fieldValue reflect.Value, v reflect.Value, set func(elem reflect.Value, i int64, u uint64, f float64, kind numberKind) Reason, kinds ...reflect.Kind) (bool, error) {
+	typeOfElem := fieldValue.Type()
+	isPointer := typeOfElem.Kind() == reflect.Ptr
+	if isPointer {
+		typeOfElem = typeOfElem.Elem()
+	}
+	// a named number with an unmarshaler is left to it, e.g. an amount of cents unmarshaled from 1.5
+	if !hasKind(typeOfElem, kinds) || ownsUnmarshaler(typeOfElem) {
+		return false, nil
+	}
 
+	i, u, f, kind := numberOf(v)
+	switch {
+	case kind == nullNumber && isPointer:
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return true, nil
+	case kind == nullNumber || kind == notNumber:
+		return false, nil
 	}
 
-	return false
+	elem := fieldValue
+	if isPointer {
+		elem = reflect.New(typeOfElem).Elem()
+	}
+	if reason := set(elem, i, u, f, kind); reason != "" {
+		return true, reason
+	}
+	if isPointer {
+		fieldValue.Set(elem.Addr())
+	}
+	return true, nil
 }
 
-// TimeUpdater update time (pointer or value)
-func TimeUpdater(fieldValue reflect.Value, v reflect.Value) bool {
-	switch fieldValue.Interface().(type) {
-	case time.Time:
-		if !v.IsValid() {
-			return false
-		}
-
-		switch v.Kind() {
-		case reflect.Int64:
-			t := time.Unix(v.Int(), 0)
-			fieldValue.Set(reflect.ValueOf(t))
-			return true
-		case reflect.Float64:
-			t := time.Unix(int64(v.Float()), 0)
-			fieldValue.Set(reflect.ValueOf(t))
-			return true
-		case reflect.String:
-			t := time.Now()
-			// make sure date format is correct
-			if err := t.UnmarshalJSON([]byte(`"` + v.String() + `"`)); err == nil {
-				newValue := reflect.ValueOf(t)
-				fieldValue.Set(newValue)
-				return true
-			}
-		}
-	case *time.Time:
-		if !v.IsValid() {
-			var t *time.Time
-			newValue := reflect.ValueOf(t)
-			fieldValue.Set(newValue)
-			return true
-		}
-
-		switch v.Kind() {
-		case reflect.Int64:
-			t := time.Unix(v.Int(), 0)
-			fieldValue.Set(reflect.ValueOf(&t))
+func hasKind(t reflect.Type, kinds []reflect.Kind) bool {
+	for _, kind := range kinds {
+		if t.Kind() == kind {
 			return true
-		case reflect.Float64:
-			t := time.Unix(int64(v.Float()), 0)
-			fieldValue.Set(reflect.ValueOf(&t))
-			return true
-		case reflect.String:
-			t := time.Now()
-			// make sure date format is correct
-			if err := t.UnmarshalJSON([]byte(`"` + v.String() + `"`)); err == nil {
-				newValue := reflect.ValueOf(&t)
-				fieldValue.Set(newValue)
-				return true
-			}
 		}
 	}
-
 	return false
 }
 
-func isOverflowUint(field interface{}, value uint64) bool {
-	return reflect.ValueOf(field).OverflowUint(value)
-}
+var (
+	typeOfTime        = reflect.TypeOf(time.Time{})
+	typeOfTimePointer = reflect.TypeOf(&time.Time{})
+)
+
+// UpdateTime is the Updater of TimeUpdater: an int64 or float64 unix time or a RFC 3339 string
+// is converted to a time (pointer or value), a string that is not a valid time is rejected
+// with ReasonUnparseableTime
+func UpdateTime(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	if fieldValue.Type() != typeOfTime && fieldValue.Type() != typeOfTimePointer {
+		return false, nil
+	}
+
+	var t time.Time
+	switch v.Kind() {
+	case reflect.Invalid:
+		if fieldValue.Type() != typeOfTimePointer {
+			return false, nil
+		}
+		fieldValue.Set(reflect.Zero(typeOfTimePointer))
+		return true, nil
+	case reflect.Int64:
+		t = time.Unix(v.Int(), 0)
+	case reflect.Float64:
+		t = time.Unix(int64(v.Float()), 0)
+	case reflect.String:
+		// make sure date format is correct
+		if err := t.UnmarshalJSON([]byte(`"` + v.String() + `"`)); err != nil {
+			return true, ReasonUnparseableTime
+		}
+	default:
+		return false, nil
+	}
 
-func isOverflowInt(field interface{}, value int64) bool {
-	return reflect.ValueOf(field).OverflowInt(value)
+	if fieldValue.Type() == typeOfTimePointer {
+		fieldValue.Set(reflect.ValueOf(&t))
+	} else {
+		fieldValue.Set(reflect.ValueOf(t))
+	}
+	return true, nil
 }
 
-func isOverflowFloat(field interface{}, value float64) bool {
-	return reflect.ValueOf(field).OverflowFloat(value)
+// succeeded converts the result of an Updater to the result of an updater returning a bool
+func succeeded(handled bool, err error) bool {
+	return handled && err == nil
 }
 
 // AllUpdaters is a collection of all type updaters
@@ -843,6 +517,8 @@ var AllUpdaters = []func(reflect.Value, reflect.Value) bool{
 	NullIntUpdater,
 	NullBoolUpdater,
 	NullTimeUpdater,
+	SQLNullUpdater,
+	NullTypeUpdater,
 	MapStringInterfaceUpdater,
 	IntUpdater,
 	UintUpdater,
@@ -859,3 +535,52 @@ var Updaters = []func(reflect.Value, reflect.Value) bool{
 	TimeUpdater,
 	BoolUpdater,
 }
+
+// Updater updates a field like the updaters returning a bool, and also tells why a value is rejected.
+// handled is false when the updater does not apply to the field or the value, the next updater is then tried.
+// err is not nil when the value is rejected, a Reason or an error wrapping one sets the Reason of the FieldError.
+type Updater interface {
+	Update(fieldValue reflect.Value, v reflect.Value) (handled bool, err error)
+}
+
+// UpdaterFunc is a function used as an Updater
+type UpdaterFunc func(fieldValue reflect.Value, v reflect.Value) (bool, error)
+
+// Update calls f(fieldValue, v)
+func (f UpdaterFunc) Update(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	return f(fieldValue, v)
+}
+
+// BoolFunc is an updater returning a bool used as an Updater, false means not handled
+type BoolFunc func(fieldValue reflect.Value, v reflect.Value) bool
+
+// Update calls f(fieldValue, v)
+func (f BoolFunc) Update(fieldValue reflect.Value, v reflect.Value) (bool, error) {
+	return f(fieldValue, v), nil
+}
+
+// AllCheckedUpdaters is a collection of all type updaters telling why a value is rejected
+var AllCheckedUpdaters = []Updater{
+	UpdaterFunc(UpdateNullString),
+	UpdaterFunc(UpdateNullFloat),
+	UpdaterFunc(UpdateNullInt),
+	UpdaterFunc(UpdateNullBool),
+	UpdaterFunc(UpdateNullTime),
+	UpdaterFunc(UpdateSQLNull),
+	UpdaterFunc(UpdateNullType),
+	BoolFunc(MapStringInterfaceUpdater),
+	UpdaterFunc(UpdateInt),
+	UpdaterFunc(UpdateUint),
+	UpdaterFunc(UpdateFloat),
+	UpdaterFunc(UpdateTime),
+	UpdaterFunc(UpdateBool),
+}
+
+// CheckedUpdaters is a collection of standard type updaters telling why a value is rejected
+var CheckedUpdaters = []Updater{
+	UpdaterFunc(UpdateInt),
+	UpdaterFunc(UpdateUint),
+	UpdaterFunc(UpdateFloat),
+	UpdaterFunc(UpdateTime),
+	UpdaterFunc(UpdateBool),
+}